@@ -9,6 +9,8 @@ import (
 	"github.com/giantswarm/micrologger"
 	"github.com/spf13/cobra"
 
+	"github.com/giantswarm/api-acceptance-test/cmd/kubeconfig"
+	"github.com/giantswarm/api-acceptance-test/cmd/load"
 	"github.com/giantswarm/api-acceptance-test/cmd/runtests"
 	"github.com/giantswarm/api-acceptance-test/cmd/version"
 )
@@ -84,6 +86,36 @@ func New(config Config) (*cobra.Command, error) {
 	}
 	c.AddCommand(runTestsCmd)
 
+	var kubeconfigCmd *cobra.Command
+	{
+		c := kubeconfig.Config{
+			Logger: config.Logger,
+			Stderr: config.Stderr,
+			Stdout: config.Stdout,
+		}
+
+		kubeconfigCmd, err = kubeconfig.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	c.AddCommand(kubeconfigCmd)
+
+	var loadCmd *cobra.Command
+	{
+		c := load.Config{
+			Logger: config.Logger,
+			Stderr: config.Stderr,
+			Stdout: config.Stdout,
+		}
+
+		loadCmd, err = load.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	c.AddCommand(loadCmd)
+
 	var versionCmd *cobra.Command
 	{
 		c := version.Config{
@@ -0,0 +1,75 @@
+// Package kubeconfig represents the kubeconfig command and its subcommands.
+package kubeconfig
+
+import (
+	"io"
+	"os"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/api-acceptance-test/cmd/kubeconfig/inspect"
+)
+
+const (
+	name        = "kubeconfig"
+	description = "Works with kubeconfig files created by the tests."
+)
+
+// Config configures the kubeconfig command.
+type Config struct {
+	Logger micrologger.Logger
+	Stderr io.Writer
+	Stdout io.Writer
+}
+
+// New instantiates the kubeconfig command.
+func New(config Config) (*cobra.Command, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Stderr == nil {
+		config.Stderr = os.Stderr
+	}
+	if config.Stdout == nil {
+		config.Stdout = os.Stdout
+	}
+
+	var err error
+
+	f := &flag{}
+
+	r := &runner{
+		flag:   f,
+		logger: config.Logger,
+		stderr: config.Stderr,
+		stdout: config.Stdout,
+	}
+
+	c := &cobra.Command{
+		Use:   name,
+		Short: description,
+		Long:  description,
+		RunE:  r.Run,
+	}
+
+	f.Init(c)
+
+	var inspectCmd *cobra.Command
+	{
+		c := inspect.Config{
+			Logger: config.Logger,
+			Stderr: config.Stderr,
+			Stdout: config.Stdout,
+		}
+
+		inspectCmd, err = inspect.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	c.AddCommand(inspectCmd)
+
+	return c, nil
+}
@@ -0,0 +1,57 @@
+// Package inspect represents the kubeconfig inspect command.
+package inspect
+
+import (
+	"io"
+	"os"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	name        = "inspect <path>"
+	description = "Validates a kubeconfig file, shows its certificates and checks whether the API server is reachable."
+)
+
+// Config configures the inspect command.
+type Config struct {
+	Logger micrologger.Logger
+	Stderr io.Writer
+	Stdout io.Writer
+}
+
+// New instantiates the inspect command.
+func New(config Config) (*cobra.Command, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Stderr == nil {
+		config.Stderr = os.Stderr
+	}
+	if config.Stdout == nil {
+		config.Stdout = os.Stdout
+	}
+
+	f := &flag{}
+
+	r := &runner{
+		flag:   f,
+		logger: config.Logger,
+		stderr: config.Stderr,
+		stdout: config.Stdout,
+	}
+
+	c := &cobra.Command{
+		Use:   name,
+		Short: description,
+		Long:  description,
+		Args:  cobra.ExactArgs(1),
+		RunE:  r.Run,
+	}
+
+	f.Init(c)
+
+	return c, nil
+}
@@ -0,0 +1,30 @@
+package inspect
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var invalidFlagsError = &microerror.Error{
+	Kind: "invalidFlagsError",
+}
+
+// IsInvalidFlags asserts invalidFlagsError.
+func IsInvalidFlags(err error) bool {
+	return microerror.Cause(err) == invalidFlagsError
+}
+
+var inspectionFailedError = &microerror.Error{
+	Kind: "inspectionFailedError",
+}
+
+// IsInspectionFailed asserts inspectionFailedError.
+func IsInspectionFailed(err error) bool {
+	return microerror.Cause(err) == inspectionFailedError
+}
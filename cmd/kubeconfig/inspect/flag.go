@@ -0,0 +1,24 @@
+package inspect
+
+import (
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/cobra"
+)
+
+type flag struct {
+	Timeout time.Duration
+}
+
+func (f *flag) Init(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&f.Timeout, "timeout", 10*time.Second, "Maximum time to wait for the API server to respond.")
+}
+
+func (f *flag) Validate() error {
+	if f.Timeout <= 0 {
+		return microerror.Maskf(invalidFlagsError, "flag --timeout must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/kubeconfig"
+)
+
+type runner struct {
+	flag   *flag
+	logger micrologger.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// Run is called when the inspect command is executed.
+func (r *runner) Run(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	err := r.flag.Validate()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = r.run(ctx, cmd, args)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *runner) run(ctx context.Context, cmd *cobra.Command, args []string) error {
+	path := args[0]
+
+	kc, err := kubeconfig.Load(afero.NewOsFs(), path)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	fmt.Fprintf(r.stdout, "Current context: %s\n", kc.CurrentContext)
+	for _, name := range kubeconfig.ContextNames(kc) {
+		c := kc.Contexts[name]
+		fmt.Fprintf(r.stdout, "Context %s: cluster %s, user %s\n", name, c.Cluster, c.AuthInfo)
+	}
+	for _, name := range kubeconfig.ClusterNames(kc) {
+		fmt.Fprintf(r.stdout, "Cluster %s: %s\n", name, kc.Clusters[name].Server)
+	}
+
+	problems := 0
+
+	err = kubeconfig.Validate(kc)
+	if err != nil {
+		problems++
+		fmt.Fprintln(r.stdout, color.RedString("Invalid: %s", err.Error()))
+	} else {
+		fmt.Fprintln(r.stdout, color.GreenString("Valid"))
+	}
+
+	certs, err := kubeconfig.Certificates(kc)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	now := time.Now()
+	fmt.Fprintln(r.stdout, "\nCertificates:")
+	if len(certs) == 0 {
+		fmt.Fprintln(r.stdout, "  none")
+	}
+	for _, c := range certs {
+		fmt.Fprintf(r.stdout, "  %s of %s\n", c.Field, c.Entry)
+		fmt.Fprintf(r.stdout, "    Subject:       %s\n", c.Subject)
+		if len(c.Organizations) > 0 {
+			fmt.Fprintf(r.stdout, "    Organizations: %s\n", strings.Join(c.Organizations, ", "))
+		}
+		fmt.Fprintf(r.stdout, "    Not before:    %s\n", c.NotBefore)
+		if c.Expired(now) {
+			problems++
+			fmt.Fprintf(r.stdout, "    Not after:     %s\n", color.RedString("%s (expired)", c.NotAfter))
+		} else {
+			fmt.Fprintf(r.stdout, "    Not after:     %s (expires in %s)\n", c.NotAfter, c.NotAfter.Sub(now).Round(time.Minute))
+		}
+	}
+
+	if kc.CurrentContext != "" {
+		fmt.Fprintln(r.stdout)
+		info, err := kubeconfig.CheckServer(kc, r.flag.Timeout)
+		if kubeconfig.IsInvalidKubeconfig(err) {
+			problems++
+			fmt.Fprintln(r.stdout, color.RedString("API server not checked: %s", err.Error()))
+		} else if kubeconfig.IsUnreachable(err) {
+			problems++
+			fmt.Fprintln(r.stdout, color.RedString("API server not reachable: %s", err.Error()))
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else {
+			fmt.Fprintln(r.stdout, color.GreenString("API server %s responded with status %d", info.Server, info.StatusCode))
+			if info.GitVersion != "" {
+				fmt.Fprintf(r.stdout, "Kubernetes version: %s\n", info.GitVersion)
+			}
+		}
+	}
+
+	if problems > 0 {
+		return microerror.Maskf(inspectionFailedError, "found %d problems in %s", problems, path)
+	}
+
+	return nil
+}
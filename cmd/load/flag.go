@@ -0,0 +1,68 @@
+package load
+
+import (
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+type flag struct {
+	Concurrency    int
+	Duration       time.Duration
+	Interval       time.Duration
+	MetricsAddress string
+	NewConnections bool
+	Output         string
+	Profile        string
+	RampUp         time.Duration
+	Rate           float64
+	Timeout        time.Duration
+}
+
+func (f *flag) Init(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.Concurrency, "concurrency", 10, "Maximum number of requests in flight.")
+	cmd.Flags().DurationVar(&f.Duration, "duration", time.Minute, "How long to generate load. Set 0 to run until interrupted.")
+	cmd.Flags().DurationVar(&f.Interval, "interval", 10*time.Second, "Period of time each line of the live summary covers.")
+	cmd.Flags().StringVar(&f.MetricsAddress, "metrics-address", "", "Address to serve Prometheus metrics at /metrics on while load is generated, e.g. ':9090'. Leave empty to not serve metrics.")
+	cmd.Flags().BoolVar(&f.NewConnections, "new-connections", false, "Set to true to open a new connection for every request instead of keeping connections alive.")
+	cmd.Flags().StringVar(&f.Output, "output", outputText, "Output format, either 'text' or 'json'. JSON is written as one object per line.")
+	cmd.Flags().StringVar(&f.Profile, "profile", "", "Path of a YAML file defining the mix of requests to send, with paths resolved against the URL. By default the URL is requested with GET.")
+	cmd.Flags().DurationVar(&f.RampUp, "ramp-up", 0, "Increase the rate linearly from zero to --rate within this period of time.")
+	cmd.Flags().Float64Var(&f.Rate, "rate", 0, "Requests per second. Set 0 to send the next request as soon as a previous one is done.")
+	cmd.Flags().DurationVar(&f.Timeout, "timeout", 10*time.Second, "Maximum time for each request.")
+}
+
+func (f *flag) Validate() error {
+	if f.Concurrency <= 0 {
+		return microerror.Maskf(invalidFlagsError, "flag --concurrency must be positive")
+	}
+	if f.Duration < 0 {
+		return microerror.Maskf(invalidFlagsError, "flag --duration must not be negative")
+	}
+	if f.Interval <= 0 {
+		return microerror.Maskf(invalidFlagsError, "flag --interval must be positive")
+	}
+	if f.Output != outputText && f.Output != outputJSON {
+		return microerror.Maskf(invalidFlagsError, "flag --output must be either '%s' or '%s'", outputText, outputJSON)
+	}
+	if f.Rate < 0 {
+		return microerror.Maskf(invalidFlagsError, "flag --rate must not be negative")
+	}
+	if f.RampUp < 0 {
+		return microerror.Maskf(invalidFlagsError, "flag --ramp-up must not be negative")
+	}
+	if f.RampUp > 0 && f.Rate == 0 {
+		return microerror.Maskf(invalidFlagsError, "flag --ramp-up requires flag --rate")
+	}
+	if f.Timeout <= 0 {
+		return microerror.Maskf(invalidFlagsError, "flag --timeout must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package load
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/load"
+	"github.com/giantswarm/api-acceptance-test/pkg/metrics"
+)
+
+type runner struct {
+	flag   *flag
+	logger micrologger.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// Run is called when the load command is executed.
+func (r *runner) Run(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	err := r.flag.Validate()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = r.run(ctx, cmd, args)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *runner) run(ctx context.Context, cmd *cobra.Command, args []string) error {
+	config := load.Config{
+		URL:            args[0],
+		Workers:        r.flag.Concurrency,
+		Rate:           r.flag.Rate,
+		Timeout:        r.flag.Timeout,
+		NewConnections: r.flag.NewConnections,
+		Interval:       r.flag.Interval,
+		Observe:        metrics.ObserveLoadResult,
+	}
+	if r.flag.RampUp > 0 {
+		config.Rate = 0
+		config.Shape = load.Ramp{From: 0, To: r.flag.Rate, Duration: r.flag.RampUp}
+	}
+	if r.flag.Profile != "" {
+		profile, err := load.LoadProfile(afero.NewOsFs(), r.flag.Profile)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		config = profile.Apply(config)
+		// The flag is the only way to ask for new connections when the
+		// profile doesn't.
+		config.NewConnections = config.NewConnections || r.flag.NewConnections
+	}
+
+	switch r.flag.Output {
+	case outputJSON:
+		config.Sinks = []load.Sink{load.NewJSONSink(r.stdout)}
+	default:
+		// Only intervals make up the live summary. The overall statistics
+		// are printed as a report at the end.
+		live := load.NewWriterSink(r.stdout)
+		config.Sinks = []load.Sink{load.SinkFunc(func(period load.Period, stats load.Stats) error {
+			if period != load.PeriodInterval {
+				return nil
+			}
+			return live.Write(period, stats)
+		})}
+	}
+
+	generator, err := load.New(config)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if r.flag.MetricsAddress != "" {
+		server, err := metrics.Serve(r.flag.MetricsAddress)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		defer server.Close()
+		fmt.Fprintf(r.stderr, "Serving metrics at http://%s/metrics\n", r.flag.MetricsAddress)
+	}
+
+	if r.flag.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.flag.Duration)
+		defer cancel()
+	}
+
+	// Stop gracefully on Ctrl+C, so that the report still gets printed.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	if r.flag.Output == outputText {
+		fmt.Fprintf(r.stderr, "Generating load on %s, press Ctrl+C to stop\n", config.URL)
+	}
+
+	err = generator.Start(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	select {
+	case <-generator.Done():
+	case <-interrupt:
+	}
+
+	err = generator.Stop()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if r.flag.Output == outputText {
+		printReport(r.stdout, generator.Results().Overall)
+	}
+
+	return nil
+}
+
+// printReport prints the statistics of the whole run in a human readable
+// form.
+func printReport(w io.Writer, s load.Stats) {
+	fmt.Fprintln(w, "\nReport:")
+	fmt.Fprintf(w, "  Duration:       %s\n", s.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "  Requests:       %d (%.1f/s)\n", s.Requests, s.RequestRate())
+	fmt.Fprintf(w, "  Successes:      %d\n", s.Successes)
+	fmt.Fprintf(w, "  Failures:       %d\n", s.Failures)
+	fmt.Fprintf(w, "  Dropped:        %d\n", s.Dropped)
+	fmt.Fprintf(w, "  Error rate:     %.3f%%\n", s.ErrorRate()*100)
+	fmt.Fprintf(w, "  Longest outage: %s\n", s.LongestOutage)
+	fmt.Fprintf(w, "  Latency:        p50 %s, p90 %s, p99 %s, max %s, mean %s\n",
+		s.Latency.P50, s.Latency.P90, s.Latency.P99, s.Latency.Max, s.Latency.Mean)
+
+	var codes []string
+	for code, count := range s.StatusCodes {
+		codes = append(codes, fmt.Sprintf("%d=%d", code, count))
+	}
+	fmt.Fprintf(w, "  Status codes:   %s\n", joinSorted(codes))
+
+	var errors []string
+	for category, count := range s.Errors {
+		errors = append(errors, fmt.Sprintf("%s=%d", category, count))
+	}
+	fmt.Fprintf(w, "  Errors:         %s\n", joinSorted(errors))
+}
+
+func joinSorted(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	sort.Strings(items)
+
+	return strings.Join(items, " ")
+}
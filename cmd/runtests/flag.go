@@ -1,30 +1,65 @@
 package runtests
 
 import (
+	"time"
+
 	"github.com/giantswarm/microerror"
 	"github.com/spf13/cobra"
 )
 
 type flag struct {
 	AuthToken         string
+	AutoscalerTimeout time.Duration
 	ClusterID         string
 	EnableLogging     bool
 	Endpoint          string
+	FastMode          bool
 	FirstNodePoolID   string
+	InstanceTypes     []string
+	ListTimeout       time.Duration
+	LoadProfile       string
+	MergeKubeconfig   string
+	MetricsAddress    string
 	OwnerOrganization string
 	ReleaseVersion    string
+	ScalingTimeout    time.Duration
 	Scheme            string
+	SLOMaxErrorRate   float64
+	SLOMaxOutage      time.Duration
+	SpotInstances     bool
+	TestApp           bool
+	TestAppManifest   string
+	UseKubectl        bool
+
+	UnprivilegedScheme string
+	UnprivilegedToken  string
 }
 
 func (f *flag) Init(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&f.EnableLogging, "enable-logging", false, "Set to true to enable verbose stack logging on errors.")
+	cmd.Flags().DurationVar(&f.AutoscalerTimeout, "autoscaler-timeout", 30*time.Minute, "Maximum time for the cluster autoscaler to scale a node pool up or down.")
 	cmd.Flags().StringVar(&f.AuthToken, "token", "", "Use this flag to pass your auth token.")
 	cmd.Flags().StringVar(&f.ClusterID, "cluster-id", "", "Use this cluster instead of creating a new one, to take a shortcut.")
 	cmd.Flags().StringVar(&f.Endpoint, "endpoint", "", "Endpoint URL for the Giant Swarm API, without trailing slash.")
+	cmd.Flags().BoolVar(&f.FastMode, "fast", false, "Set to true to skip long waits, e.g. for key pair expiry.")
 	cmd.Flags().StringVar(&f.FirstNodePoolID, "first-nodepool-id", "", "Use this node pool as the first one instead of creating a new one, to take a shortcut.")
+	cmd.Flags().DurationVar(&f.ListTimeout, "list-consistency-timeout", 2*time.Minute, "Maximum time for a node pool change to become visible in the node pool list.")
+	cmd.Flags().StringVar(&f.LoadProfile, "load-profile", "", "Path of a YAML file defining the mix of requests to put load on the test app with, e.g. testapp-load-profile.yaml. Defaults to GET /delay/1.")
+	cmd.Flags().StringVar(&f.MergeKubeconfig, "merge-kubeconfig", "", "Path of a kubeconfig file, e.g. $KUBECONFIG, to merge the test cluster's key pair into. Entries are removed when the cluster gets deleted.")
+	cmd.Flags().StringVar(&f.MetricsAddress, "metrics-address", "", "Address to serve Prometheus metrics at /metrics on while tests run, e.g. ':9090'. Leave empty to not serve metrics.")
+	cmd.Flags().StringSliceVar(&f.InstanceTypes, "nodepool-instance-types", []string{"m5.xlarge", "m5.2xlarge", "r5.xlarge"}, "Instance types of additional node pools to create in one cluster. Set empty to skip this test.")
 	cmd.Flags().StringVar(&f.OwnerOrganization, "owner-org", "giantswarm", "Name of the organization owning created clusters.")
 	cmd.Flags().StringVar(&f.ReleaseVersion, "release-version", "", "Release version to test with, without 'v' prefix ('X.Y.Z'). Leave empty to use latest.")
+	cmd.Flags().DurationVar(&f.ScalingTimeout, "scaling-timeout", 20*time.Minute, "Maximum time for a scaled node pool to reach the desired number of nodes.")
 	cmd.Flags().StringVar(&f.Scheme, "scheme", "giantswarm", "Use 'giantswarm' for normal token auth or 'Bearer' for SSO token auth.")
+	cmd.Flags().Float64Var(&f.SLOMaxErrorRate, "slo-max-error-rate", 0.001, "Highest acceptable fraction of failed test app requests while a step runs. Set 0 to disable.")
+	cmd.Flags().DurationVar(&f.SLOMaxOutage, "slo-max-outage", 5*time.Second, "Longest acceptable test app outage while a step runs. Set 0 to disable.")
+	cmd.Flags().BoolVar(&f.SpotInstances, "spot-instances", false, "Set to true to test node pools mixing spot and on-demand instances.")
+	cmd.Flags().BoolVar(&f.TestApp, "testapp", false, "Set to true to deploy a test app, put load on it and check its availability against the SLO while node pools get scaled.")
+	cmd.Flags().StringVar(&f.TestAppManifest, "testapp-manifest", "", "Path of a Go text/template to render the test app manifest from instead of the built-in one. See pkg/testapp for the values available.")
+	cmd.Flags().BoolVar(&f.UseKubectl, "use-kubectl", false, "Set to true to access the tenant cluster via kubectl on the PATH instead of the native client.")
+	cmd.Flags().StringVar(&f.UnprivilegedScheme, "unprivileged-scheme", "giantswarm", "Auth scheme of the --unprivileged-token, either 'giantswarm' or 'Bearer'.")
+	cmd.Flags().StringVar(&f.UnprivilegedToken, "unprivileged-token", "", "Token of a user not belonging to the owner organization. If set, authorization boundaries get tested.")
 }
 
 func (f *flag) Validate() error {
@@ -34,6 +69,15 @@ func (f *flag) Validate() error {
 	if f.Scheme != "giantswarm" && f.Scheme != "Bearer" {
 		return microerror.Maskf(invalidFlagsError, "flag --scheme must be either 'Bearer' or 'giantswarm' (case sensitive!)")
 	}
+	if f.SLOMaxErrorRate < 0 || f.SLOMaxErrorRate > 1 {
+		return microerror.Maskf(invalidFlagsError, "flag --slo-max-error-rate must be between 0 and 1")
+	}
+	if f.SLOMaxOutage < 0 {
+		return microerror.Maskf(invalidFlagsError, "flag --slo-max-outage must not be negative")
+	}
+	if f.UnprivilegedScheme != "giantswarm" && f.UnprivilegedScheme != "Bearer" {
+		return microerror.Maskf(invalidFlagsError, "flag --unprivileged-scheme must be either 'Bearer' or 'giantswarm' (case sensitive!)")
+	}
 
 	return nil
 }
@@ -7,13 +7,18 @@ import (
 	"time"
 
 	"github.com/cenkalti/backoff"
-	"github.com/giantswarm/gsclientgen/client/clusters"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 
 	"github.com/giantswarm/api-acceptance-test/pkg/client"
 	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+	"github.com/giantswarm/api-acceptance-test/pkg/k8s"
+	"github.com/giantswarm/api-acceptance-test/pkg/kubeconfig"
+	"github.com/giantswarm/api-acceptance-test/pkg/load"
+	"github.com/giantswarm/api-acceptance-test/pkg/metrics"
+	"github.com/giantswarm/api-acceptance-test/pkg/testapp"
 	"github.com/giantswarm/api-acceptance-test/pkg/uat"
 )
 
@@ -42,6 +47,41 @@ func (r *runner) Run(cmd *cobra.Command, args []string) error {
 }
 
 func (r *runner) run(ctx context.Context, cmd *cobra.Command, args []string) error {
+	// Read the load profile first, so that mistakes show before any cluster
+	// gets created.
+	var loadProfile *load.Profile
+	if r.flag.TestApp && r.flag.LoadProfile != "" {
+		profile, err := load.LoadProfile(afero.NewOsFs(), r.flag.LoadProfile)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		loadProfile = &profile
+	}
+	testAppTemplate := testapp.Template
+	if r.flag.TestApp && r.flag.TestAppManifest != "" {
+		var err error
+		testAppTemplate, err = testapp.LoadTemplate(afero.NewOsFs(), r.flag.TestAppManifest)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	if r.flag.MetricsAddress != "" {
+		server, err := metrics.Serve(r.flag.MetricsAddress)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		defer server.Close()
+		cliutil.PrintInfo("Serving metrics at http://%s/metrics", r.flag.MetricsAddress)
+	}
+	// step measures the duration of the current test step.
+	var step *metrics.Step
+
+	apiClientConfig := client.Config{
+		EndpointURL:    r.flag.Endpoint,
+		ObserveRequest: metrics.ObserveAPIRequest,
+	}
+
 	// Initialize client
 	var apiClient *client.Client
 	{
@@ -49,7 +89,7 @@ func (r *runner) run(ctx context.Context, cmd *cobra.Command, args []string) err
 
 		fmt.Printf("API Endpoint: %s\n", r.flag.Endpoint)
 
-		apiClient, err = client.New(r.flag.Endpoint)
+		apiClient, err = client.New(apiClientConfig)
 		cliutil.ExitIfError(err)
 	}
 
@@ -57,6 +97,13 @@ func (r *runner) run(ctx context.Context, cmd *cobra.Command, args []string) err
 	err := uat.TestClient(apiClient)
 	cliutil.ExitIfError(err)
 
+	// Initialize the client for a user outside the owner organization.
+	var unprivilegedClient *client.Client
+	if r.flag.UnprivilegedToken != "" {
+		unprivilegedClient, err = client.NewWithToken(apiClientConfig, r.flag.UnprivilegedScheme, r.flag.UnprivilegedToken)
+		cliutil.ExitIfError(err)
+	}
+
 	var clusterOneID string
 	var clusterOneAPIEndpoint string
 	var nodePoolOneID string
@@ -64,7 +111,9 @@ func (r *runner) run(ctx context.Context, cmd *cobra.Command, args []string) err
 	if r.flag.ClusterID == "" {
 		// 1. Create a cluster with one node pool based on defaults.
 		fmt.Printf("\nStep 1 - Create a cluster with one node pool based on defaults - %s\n", time.Now())
+		step = metrics.StartStep("1")
 		clusterOneID, clusterOneAPIEndpoint, err = uat.CreateClusterUsingDefaults(apiClient, r.flag.OwnerOrganization, r.flag.ReleaseVersion)
+		step.End(err)
 		cliutil.ExitIfError(err)
 	} else {
 		clusterOneID = r.flag.ClusterID
@@ -73,25 +122,20 @@ func (r *runner) run(ctx context.Context, cmd *cobra.Command, args []string) err
 	// Workaround until step 1 returns proper cluster info.
 	if clusterOneAPIEndpoint == "" {
 		fmt.Printf("\nStep 1a - Get cluster details, so we know the API endpoint - %s\n", time.Now())
-		var params *clusters.GetClusterV5Params
-		params = clusters.NewGetClusterV5Params().WithClusterID(clusterOneID)
-		authWriter, err := apiClient.AuthHeaderWriter()
-		if err != nil {
-			cliutil.ExitIfError(microerror.Mask(err))
-		}
-
-		result, err := apiClient.GSClientGen.Clusters.GetClusterV5(params, authWriter)
-		if err != nil {
-			cliutil.ExitIfError(microerror.Mask(err))
-		}
+		step = metrics.StartStep("1a")
+		details, err := uat.GetClusterDetails(apiClient, clusterOneID)
+		step.End(err)
+		cliutil.ExitIfError(err)
 
-		clusterOneAPIEndpoint = result.Payload.APIEndpoint
+		clusterOneAPIEndpoint = details.APIEndpoint
 	}
 
 	if r.flag.FirstNodePoolID == "" {
 		// 2. Create a node pool based on defaults.
 		fmt.Printf("\nStep 2 - Create a node pool based on defaults\n")
+		step = metrics.StartStep("2")
 		nodePoolOneID, err = uat.CreateNodePoolUsingDefaults(apiClient, clusterOneID)
+		step.End(err)
 		cliutil.ExitIfError(err)
 
 		time.Sleep(1 * time.Second)
@@ -99,19 +143,58 @@ func (r *runner) run(ctx context.Context, cmd *cobra.Command, args []string) err
 		nodePoolOneID = r.flag.FirstNodePoolID
 	}
 
+	if unprivilegedClient != nil {
+		fmt.Printf("\nStep 2a - Access cluster %s as a user outside the owner organization - %s\n", clusterOneID, time.Now())
+		step = metrics.StartStep("2a")
+		err = uat.TestAuthorizationBoundaries(unprivilegedClient, clusterOneID, nodePoolOneID)
+		step.End(err)
+		cliutil.Complain(err)
+	}
+
+	fmt.Printf("\nStep 2b - Access the API with missing or invalid credentials - %s\n", time.Now())
+	step = metrics.StartStep("2b")
+	err = uat.TestAuthenticationFailures(apiClient, clusterOneID)
+	step.End(err)
+	cliutil.Complain(err)
+
+	fmt.Printf("\nStep 2c - Send invalid cluster and node pool requests - %s\n", time.Now())
+	step = metrics.StartStep("2c")
+	err = uat.TestInputValidation(apiClient, clusterOneID, nodePoolOneID, r.flag.OwnerOrganization)
+	step.End(err)
+	cliutil.Complain(err)
+
+	if len(r.flag.InstanceTypes) != 0 {
+		fmt.Printf("\nStep 2d - Create, compare and delete node pools with instance types %v - %s\n", r.flag.InstanceTypes, time.Now())
+		step = metrics.StartStep("2d")
+		err = uat.TestMultipleNodePools(apiClient, clusterOneID, r.flag.InstanceTypes)
+		step.End(err)
+		cliutil.Complain(err)
+	}
+
+	fmt.Printf("\nStep 2e - Check node pool list consistency during changes - %s\n", time.Now())
+	step = metrics.StartStep("2e")
+	err = uat.TestNodePoolListConsistency(apiClient, clusterOneID, r.flag.ListTimeout)
+	step.End(err)
+	cliutil.Complain(err)
+
 	// rename only node pool
 	fmt.Printf("\nStep 8 - Renaming only node pool %s - %s\n", nodePoolOneID, time.Now())
+	step = metrics.StartStep("8")
 	err = uat.RenameNodePool(apiClient, clusterOneID, nodePoolOneID, "First test node pool")
+	step.End(err)
 	cliutil.Complain(err)
 
 	// scale only node pool
 	fmt.Printf("\nStep 9 - Scaling only node pool %s to min=2/max=2 - %s\n", nodePoolOneID, time.Now())
+	step = metrics.StartStep("9")
 	err = uat.ScaleNodePool(apiClient, clusterOneID, nodePoolOneID, 2, 2)
+	step.End(err)
 	cliutil.Complain(err)
 
 	// Create key pair
 	kubeconfigPath := ""
 	fmt.Printf("\nStep 3 - Create a key pair for cluster %s with k8s endpoint '%s' - %s\n", clusterOneID, clusterOneAPIEndpoint, time.Now())
+	step = metrics.StartStep("3")
 	operation := func() error {
 		kubeconfigPath, err = uat.CreateKeyPair(apiClient, clusterOneID, clusterOneAPIEndpoint)
 		if err != nil {
@@ -120,56 +203,173 @@ func (r *runner) run(ctx context.Context, cmd *cobra.Command, args []string) err
 				return err
 			}
 			// Fail in other case
+			step.End(err)
 			cliutil.ExitIfError(err)
 		}
 
 		return nil
 	}
 	err = backoff.Retry(operation, backoff.NewConstantBackOff(10*time.Second))
+	step.End(err)
 	cliutil.ExitIfError(err)
 
-	// Test kubectl access
+	var installationName string
+	if r.flag.MergeKubeconfig != "" {
+		installationName, err = uat.GetInstallationName(apiClient)
+		cliutil.ExitIfError(err)
+
+		contextName, err := kubeconfig.MergeKubeconfigFile(afero.NewOsFs(), r.flag.MergeKubeconfig, kubeconfigPath, installationName, clusterOneID)
+		cliutil.ExitIfError(err)
+		cliutil.PrintInfo("Merged the key pair into kubeconfig file %s as context %s", r.flag.MergeKubeconfig, contextName)
+	}
+
+	k8sFactory := k8s.NewFactory(r.flag.UseKubectl)
+	k8sClient, err := k8sFactory(kubeconfigPath)
+	cliutil.ExitIfError(err)
+
+	// Test Kubernetes API access
 	fmt.Printf("\nStep 4 - Access cluster's K8s API %s with kubeconfig file %s - %s\n(Take your time, we wait until it succeeds.)\n", clusterOneAPIEndpoint, kubeconfigPath, time.Now())
+	step = metrics.StartStep("4")
 	operation = func() error {
-		return uat.RunKubectlCommandToTestKeyPair(kubeconfigPath)
+		return uat.TestKeyPairAccess(k8sClient)
 	}
 	err = backoff.Retry(operation, backoff.NewConstantBackOff(10*time.Second))
+	step.End(err)
 	cliutil.ExitIfError(err)
 
+	// Create and list more key pairs. The expiry of the short-lived one is
+	// checked at the end of the run, so the steps in between needn't wait.
+	fmt.Printf("\nStep 4d - Create and list key pairs with different TTLs - %s\n", time.Now())
+	step = metrics.StartStep("4d")
+	expiringKeyPair, err := uat.TestKeyPairListing(apiClient, clusterOneID, clusterOneAPIEndpoint)
+	step.End(err)
+	cliutil.Complain(err)
+
+	// Verify key pair organizations end up as RBAC groups
+	fmt.Printf("\nStep 4e - Verify permissions of a key pair with a custom organization - %s\n", time.Now())
+	step = metrics.StartStep("4e")
+	err = uat.TestKeyPairOrganizationRBAC(apiClient, k8sClient, k8sFactory, clusterOneID, clusterOneAPIEndpoint)
+	step.End(err)
+	cliutil.Complain(err)
+
+	// Access the cluster with SSO credentials
+	if apiClient.RawIDToken != "" {
+		fmt.Printf("\nStep 4f - Access cluster's K8s API with token, OIDC and exec plugin users based on the SSO ID token - %s\n", time.Now())
+		step = metrics.StartStep("4f")
+		err = uat.TestSSOKubeconfigs(apiClient, k8sFactory, clusterOneID, kubeconfigPath)
+		step.End(err)
+		cliutil.Complain(err)
+	}
+
+	// Put load on a test app, so that steps changing the cluster can be
+	// checked against the availability SLO.
+	testAppValues := testapp.DefaultValues(testapp.BaseDomainFromAPIEndpoint(clusterOneAPIEndpoint))
+	var loadGenerator *load.Generator
+	slo := load.SLO{
+		MaxErrorRate: r.flag.SLOMaxErrorRate,
+		MaxOutage:    r.flag.SLOMaxOutage,
+	}
+	if r.flag.TestApp {
+		fmt.Printf("\nStep 5 - Deploy test app - %s\n", time.Now())
+		step = metrics.StartStep("5")
+		testAppURL, err := uat.DeployTestApp(k8sClient, testAppTemplate, testAppValues)
+		step.End(err)
+		cliutil.Complain(err)
+
+		if err == nil {
+			fmt.Printf("\nStep 6 - Create load on test app - %s\n", time.Now())
+			step = metrics.StartStep("6")
+			loadGenerator, err = uat.CreateLoadOnIngress(ctx, testAppURL, loadProfile, []load.Sink{load.NewStdoutSink()})
+			step.End(err)
+			cliutil.Complain(err)
+		}
+	}
+
+	// scale only node pool and watch nodes
+	fmt.Printf("\nStep 4a - Scaling only node pool %s to min=3/max=3 and waiting for nodes - %s\n", nodePoolOneID, time.Now())
+	step = metrics.StartStep("4a")
+	err = uat.CheckAvailability(loadGenerator, slo, func() error {
+		_, err := uat.TestNodePoolScaling(apiClient, k8sClient, clusterOneID, nodePoolOneID, 3, 3, r.flag.ScalingTimeout)
+		return err
+	})
+	step.End(err)
+	cliutil.Complain(err)
+
+	// autoscale only node pool under load
+	fmt.Printf("\nStep 4b - Autoscaling only node pool %s between min=3/max=5 under synthetic load - %s\n", nodePoolOneID, time.Now())
+	step = metrics.StartStep("4b")
+	err = uat.ScaleNodePool(apiClient, clusterOneID, nodePoolOneID, 3, 5)
+	cliutil.Complain(err)
+	if err == nil {
+		err = uat.CheckAvailability(loadGenerator, slo, func() error {
+			timeline, err := uat.TestClusterAutoscaler(apiClient, k8sClient, clusterOneID, nodePoolOneID, r.flag.AutoscalerTimeout)
+			uat.PrintNodeCountTimeline(timeline)
+			return err
+		})
+		cliutil.Complain(err)
+	}
+	step.End(err)
+
+	if loadGenerator != nil {
+		fmt.Printf("\nStep 7 - Increase test app replicas - %s\n", time.Now())
+		step = metrics.StartStep("7")
+		err = uat.CheckAvailability(loadGenerator, slo, func() error {
+			return uat.IncreaseTestAppReplicas(k8sClient, testAppValues)
+		})
+		step.End(err)
+		cliutil.Complain(err)
+	}
+
+	if r.flag.SpotInstances {
+		distributions := []uat.InstanceDistribution{
+			{OnDemandBaseCapacity: 0, OnDemandPercentageAboveBaseCapacity: 0, UseAlikeInstanceTypes: true},
+			{OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 50, UseAlikeInstanceTypes: false},
+		}
+		for _, d := range distributions {
+			fmt.Printf("\nStep 4c - Create a node pool with on-demand base capacity %d and %d%% on-demand above base - %s\n", d.OnDemandBaseCapacity, d.OnDemandPercentageAboveBaseCapacity, time.Now())
+			step = metrics.StartStep("4c")
+			err = uat.TestSpotInstanceMix(apiClient, k8sClient, clusterOneID, 3, d, r.flag.ScalingTimeout)
+			step.End(err)
+			cliutil.Complain(err)
+		}
+	}
+
+	if loadGenerator != nil {
+		err = loadGenerator.Stop()
+		cliutil.Complain(err)
+		cliutil.PrintInfo("Load on test app: %s", loadGenerator.Results().Overall)
+	}
+
+	if expiringKeyPair != nil {
+		if r.flag.FastMode {
+			cliutil.PrintInfo("Skipping wait for expiry of key pair %s in fast mode", expiringKeyPair.ID)
+		} else {
+			fmt.Printf("\nStep 4g - Verify that key pair %s expired - %s\n", expiringKeyPair.ID, time.Now())
+			step = metrics.StartStep("4g")
+			err = uat.TestKeyPairExpiry(k8sFactory, expiringKeyPair)
+			step.End(err)
+			cliutil.Complain(err)
+		}
+	}
+
 	// delete only node pool
 	fmt.Printf("\nStep 10 - Deleting only node pool %s - %s\n", nodePoolOneID, time.Now())
+	step = metrics.StartStep("10")
 	err = uat.DeleteNodePool(apiClient, clusterOneID, nodePoolOneID)
+	step.End(err)
 	cliutil.Complain(err)
 
 	// Delete cluster one.
 	fmt.Printf("\nStep 20 - Delete cluster - %s\n", time.Now())
+	step = metrics.StartStep("20")
 	err = uat.DeleteCluster(apiClient, clusterOneID)
+	step.End(err)
 	cliutil.Complain(err)
 
-	// // Deploy test app
-	// fmt.Printf("\nStep 5 - Deploy test app - %s", time.Now())
-	// testAppURL, err := uat.DeployTestApp(kubeconfigPath, clusterOneAPIEndpoint)
-	// cliutil.ExitIfError(err)
-
-	// // Create load
-	// fmt.Printf("\nStep 6 - Create load on test app - %s\n", time.Now())
-	// uat.CreateLoadOnIngress(testAppURL)
-
-	// // Increase replicas
-	// fmt.Printf("\nStep 7 - Increase test app replicas - %s\n", time.Now())
-	// uat.IncreaseTestAppReplicas(kubeconfigPath)
-
-	// // wait for nodepool autoscaling
-	// cliutil.PrintInfo("Waiting 10 minutes for node pool to adapt")
-	// for i := 0; i < 10; i++ {
-	// 	time.Sleep(60 * time.Second)
-	// 	details, err := uat.GetNodePoolDetails(apiClient, clusterOneID, nodePoolOneID)
-	// 	cliutil.Complain(err)
-
-	// 	if details != nil {
-	// 		cliutil.PrintInfo("Node pool details - nodes desired: %d, nodes in state ready: %d", details.Status.Nodes, details.Status.NodesReady)
-	// 	}
-	// }
+	if r.flag.MergeKubeconfig != "" {
+		err = kubeconfig.RemoveFromKubeconfigFile(afero.NewOsFs(), r.flag.MergeKubeconfig, installationName, clusterOneID)
+		cliutil.Complain(err)
+	}
 
 	return nil
 }
@@ -1,6 +1,6 @@
 module github.com/giantswarm/api-acceptance-test
 
-go 1.14
+go 1.16
 
 require (
 	github.com/cenkalti/backoff v2.2.1+incompatible
@@ -14,8 +14,13 @@ require (
 	github.com/go-logfmt/logfmt v0.4.0 // indirect
 	github.com/go-openapi/runtime v0.19.6
 	github.com/go-openapi/strfmt v0.19.3
-	github.com/google/go-cmp v0.3.1
+	github.com/google/go-cmp v0.4.0
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/prometheus/client_golang v1.7.1
 	github.com/spf13/afero v1.2.2
 	github.com/spf13/cobra v0.0.5
 	gopkg.in/yaml.v2 v2.2.8
+	k8s.io/api v0.17.5
+	k8s.io/apimachinery v0.17.5
+	k8s.io/client-go v0.17.5
 )
@@ -0,0 +1,122 @@
+// Package certificate decodes and checks PEM encoded X.509 certificates and
+// private keys, as handed out in key pairs.
+package certificate
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Parse decodes the first PEM encoded certificate in the given data.
+func Parse(pemData string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, microerror.Maskf(invalidDataError, "no PEM data found")
+	}
+	if block.Type != "CERTIFICATE" {
+		return nil, microerror.Maskf(invalidDataError, "expected PEM block of type CERTIFICATE, got %s", block.Type)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, microerror.Maskf(invalidDataError, err.Error())
+	}
+
+	return cert, nil
+}
+
+// VerifyChain checks that the certificate has been issued by the CA
+// certificate in caPEM and is valid for client authentication at the given
+// time.
+func VerifyChain(cert *x509.Certificate, caPEM string, at time.Time) error {
+	ca, err := Parse(caPEM)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	opts := x509.VerifyOptions{
+		Roots:       roots,
+		CurrentTime: at,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	_, err = cert.Verify(opts)
+	if err != nil {
+		return microerror.Maskf(verificationFailedError, err.Error())
+	}
+
+	return nil
+}
+
+// VerifyKeyPair checks that the PEM encoded private key in keyPEM belongs
+// to the certificate's public key.
+func VerifyKeyPair(cert *x509.Certificate, keyPEM string) error {
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var public crypto.PublicKey
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		public = &k.PublicKey
+	case *ecdsa.PrivateKey:
+		public = &k.PublicKey
+	default:
+		return microerror.Maskf(invalidDataError, "unsupported private key type %T", key)
+	}
+
+	keyDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	certDER, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if !bytes.Equal(keyDER, certDER) {
+		return microerror.Maskf(verificationFailedError, "private key does not match the certificate's public key")
+	}
+
+	return nil
+}
+
+func parsePrivateKey(keyPEM string) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, microerror.Maskf(invalidDataError, "no PEM data found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, microerror.Maskf(invalidDataError, err.Error())
+		}
+		return key, nil
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, microerror.Maskf(invalidDataError, err.Error())
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, microerror.Maskf(invalidDataError, err.Error())
+		}
+		return key, nil
+	}
+
+	return nil, microerror.Maskf(invalidDataError, "unsupported PEM block type %s", block.Type)
+}
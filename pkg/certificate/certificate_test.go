@@ -0,0 +1,153 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_Verify(t *testing.T) {
+	caKey, caPEM, ca := newCA(t)
+	_, otherCAPEM, _ := newCA(t)
+
+	certKey, certPEM := newClientCert(t, ca, caKey)
+	otherKey, _ := newClientCert(t, ca, caKey)
+
+	testCases := []struct {
+		name              string
+		caPEM             string
+		keyPEM            string
+		at                time.Time
+		chainErrMatcher   func(error) bool
+		keyPairErrMatcher func(error) bool
+	}{
+		{
+			name:   "case 0: valid chain and matching key",
+			caPEM:  caPEM,
+			keyPEM: encodeKey(certKey),
+			at:     time.Now(),
+		},
+		{
+			name:            "case 1: wrong CA",
+			caPEM:           otherCAPEM,
+			keyPEM:          encodeKey(certKey),
+			at:              time.Now(),
+			chainErrMatcher: IsVerificationFailed,
+		},
+		{
+			name:              "case 2: key of another certificate",
+			caPEM:             caPEM,
+			keyPEM:            encodeKey(otherKey),
+			at:                time.Now(),
+			keyPairErrMatcher: IsVerificationFailed,
+		},
+		{
+			name:            "case 3: expired",
+			caPEM:           caPEM,
+			keyPEM:          encodeKey(certKey),
+			at:              time.Now().Add(48 * time.Hour),
+			chainErrMatcher: IsVerificationFailed,
+		},
+		{
+			name:              "case 4: garbage key",
+			caPEM:             caPEM,
+			keyPEM:            "foo",
+			at:                time.Now(),
+			keyPairErrMatcher: IsInvalidData,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cert, err := Parse(certPEM)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			err = VerifyChain(cert, tc.caPEM, tc.at)
+			checkError(t, tc.name, err, tc.chainErrMatcher)
+
+			err = VerifyKeyPair(cert, tc.keyPEM)
+			checkError(t, tc.name, err, tc.keyPairErrMatcher)
+		})
+	}
+}
+
+func Test_Parse_Invalid(t *testing.T) {
+	_, err := Parse("not PEM")
+	if !IsInvalidData(err) {
+		t.Fatalf("error == %#v, want invalidDataError", err)
+	}
+}
+
+func checkError(t *testing.T, name string, err error, matcher func(error) bool) {
+	switch {
+	case err == nil && matcher == nil:
+		// correct; carry on
+	case err != nil && matcher == nil:
+		t.Fatalf("%s: error == %#v, want nil", name, err)
+	case err == nil && matcher != nil:
+		t.Fatalf("%s: error == nil, want non-nil", name)
+	case !matcher(err):
+		t.Fatalf("%s: error == %#v, want matching", name, err)
+	}
+}
+
+func newCA(t *testing.T) (*rsa.PrivateKey, string, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), cert
+}
+
+func newClientCert(t *testing.T, ca *x509.Certificate, caKey *rsa.PrivateKey) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "user@example.com", Organization: []string{"system:masters"}},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(12 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func encodeKey(key *rsa.PrivateKey) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
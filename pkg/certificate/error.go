@@ -0,0 +1,24 @@
+package certificate
+
+import "github.com/giantswarm/microerror"
+
+// invalidDataError is used when PEM data can't be decoded.
+var invalidDataError = &microerror.Error{
+	Kind: "invalidDataError",
+}
+
+// IsInvalidData asserts invalidDataError.
+func IsInvalidData(err error) bool {
+	return microerror.Cause(err) == invalidDataError
+}
+
+// verificationFailedError is used when a certificate or key doesn't pass
+// verification.
+var verificationFailedError = &microerror.Error{
+	Kind: "verificationFailedError",
+}
+
+// IsVerificationFailed asserts verificationFailedError.
+func IsVerificationFailed(err error) bool {
+	return microerror.Cause(err) == verificationFailedError
+}
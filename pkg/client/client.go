@@ -1,10 +1,14 @@
 package client
 
 import (
+	"bytes"
 	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	jwtgo "github.com/dgrijalva/jwt-go"
 	"github.com/giantswarm/gscliauth/oidc"
@@ -15,38 +19,56 @@ import (
 	"github.com/go-openapi/strfmt"
 )
 
+// Config configures an API client.
+type Config struct {
+	// EndpointURL is the URL of the Giant Swarm API, without trailing slash.
+	EndpointURL string
+	// ObserveRequest is called after every API request with the operation,
+	// the response status and the latency. The status is 0 if there was no
+	// response. Optional.
+	ObserveRequest func(operation string, status int, duration time.Duration)
+}
+
 // Client is our API client.
 type Client struct {
 	APIEndpointURL string
 
+	// AuthScheme is the scheme used in the Authorization header,
+	// either "Bearer" or "giantswarm".
+	AuthScheme string
+
 	IDToken      *oidc.IDToken
 	AccessToken  string
 	RefreshToken string
+	// RawIDToken is the encoded ID token of an SSO login, which can be used
+	// to authenticate against tenant clusters.
+	RawIDToken string
 
 	GSClientGen *gsclient.Gsclientgen
-}
 
-// New returns a fully conifgured API client and initiates the browser auth flow.
-func New(endpointURL string) (*Client, error) {
-	u, err := url.Parse(endpointURL)
-	if err != nil {
-		return nil, microerror.Maskf(invalidConfigError, "invalid endpoint URL")
-	}
+	httpClient     *http.Client
+	observeRequest func(operation string, status int, duration time.Duration)
+}
 
-	tlsConfig := &tls.Config{}
+// ResponseError is returned by DoRawRequest when the API responds with
+// a status code of 400 or above.
+type ResponseError struct {
+	StatusCode int
+	Body       []byte
+}
 
-	transport := httptransport.New(u.Host, "", []string{u.Scheme})
-	transport.Transport = &http.Transport{
-		Proxy:           http.ProxyFromEnvironment,
-		TLSClientConfig: tlsConfig,
-	}
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("API responded with status %d: %s", e.StatusCode, string(e.Body))
+}
 
-	c := &Client{
-		APIEndpointURL: endpointURL,
-		GSClientGen:    gsclient.New(transport, strfmt.Default),
+// New returns a fully conifgured API client and initiates the browser auth flow.
+func New(config Config) (*Client, error) {
+	c, err := newClient(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
 	}
 
-	pkceResponse, err := oidc.RunPKCE(endpointURL)
+	pkceResponse, err := oidc.RunPKCE(config.EndpointURL)
 	if err != nil {
 		fmt.Println("DEBUG: Attempt to run the OAuth2 PKCE workflow with a local callback HTTP server failed.")
 		return nil, microerror.Mask(err)
@@ -61,25 +83,119 @@ func New(endpointURL string) (*Client, error) {
 	c.IDToken = idToken
 	c.AccessToken = pkceResponse.AccessToken
 	c.RefreshToken = pkceResponse.RefreshToken
+	c.RawIDToken = pkceResponse.IDToken
+
+	return c, nil
+}
+
+// NewWithToken returns an API client using the given static token,
+// without running the browser auth flow. The token is never refreshed.
+func NewWithToken(config Config, scheme string, token string) (*Client, error) {
+	if token == "" {
+		return nil, microerror.Maskf(invalidConfigError, "token must not be empty")
+	}
+	if scheme != "giantswarm" && scheme != "Bearer" {
+		return nil, microerror.Maskf(invalidConfigError, "scheme must be either 'Bearer' or 'giantswarm'")
+	}
+
+	c, err := newClient(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c.AuthScheme = scheme
+	c.AccessToken = token
+
+	return c, nil
+}
+
+func newClient(config Config) (*Client, error) {
+	u, err := url.Parse(config.EndpointURL)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "invalid endpoint URL")
+	}
+
+	tlsConfig := &tls.Config{}
+
+	httpTransport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+
+	transport := httptransport.New(u.Host, "", []string{u.Scheme})
+	transport.Transport = httpTransport
+
+	observeRequest := config.ObserveRequest
+	if observeRequest == nil {
+		observeRequest = func(string, int, time.Duration) {}
+	}
+
+	c := &Client{
+		APIEndpointURL: config.EndpointURL,
+		AuthScheme:     "Bearer",
+		GSClientGen:    gsclient.New(&instrumentedTransport{transport: transport, httpTransport: httpTransport, observe: observeRequest}, strfmt.Default),
+
+		httpClient:     &http.Client{Transport: httpTransport},
+		observeRequest: observeRequest,
+	}
 
 	return c, nil
 }
 
 // AuthHeaderWriter returns a function to write an authentication header.
 func (c *Client) AuthHeaderWriter() (runtime.ClientAuthInfoWriter, error) {
-	authHeader := "Bearer " + c.MustGetToken()
+	authHeader := c.AuthScheme + " " + c.MustGetToken()
 	return httptransport.APIKeyAuth("Authorization", "header", authHeader), nil
 }
 
+// DoRawRequest sends a request with the given JSON body to the API path,
+// bypassing the generated client. This allows sending bodies which
+// don't fit the API models. Responses with status code 400 or above are
+// returned as *ResponseError.
+func (c *Client) DoRawRequest(method string, path string, body []byte) (int, []byte, error) {
+	u := strings.TrimSuffix(c.APIEndpointURL, "/") + path
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, microerror.Mask(err)
+	}
+	req.Header.Set("Authorization", c.AuthScheme+" "+c.MustGetToken())
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.observeRequest(rawOperation(method), 0, time.Since(start))
+		return 0, nil, microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+	c.observeRequest(rawOperation(method), resp.StatusCode, time.Since(start))
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, microerror.Mask(err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return resp.StatusCode, respBody, &ResponseError{StatusCode: resp.StatusCode, Body: respBody}
+	}
+
+	return resp.StatusCode, respBody, nil
+}
+
 // GetToken returns a token to use for the API client's Authorization header.
 // If necessary, refreshes the token.
 func (c *Client) GetToken() (string, error) {
+	// Static tokens are used as they are.
+	if c.RefreshToken == "" && c.AccessToken != "" {
+		return c.AccessToken, nil
+	}
+
 	// Check if it has a refresh token.
 	if c.RefreshToken == "" {
 		return "", microerror.Maskf(invalidConfigError, "No refresh token saved in config file, unable to acquire new access token. Please login again.")
 	}
 
-	if isTokenExpired(c.AccessToken) {
+	if IsTokenExpired(c.AccessToken) {
 		// Get a new token.
 		refreshTokenResponse, err := oidc.RefreshToken(c.RefreshToken)
 		if err != nil {
@@ -95,6 +211,7 @@ func (c *Client) GetToken() (string, error) {
 		// Update the config file with the new access token.
 		c.IDToken = idToken
 		c.AccessToken = refreshTokenResponse.AccessToken
+		c.RawIDToken = refreshTokenResponse.IDToken
 
 		fmt.Println("DEBUG: Access token has just been refreshed.")
 	}
@@ -112,8 +229,9 @@ func (c *Client) MustGetToken() string {
 	return token
 }
 
-// isTokenExpired checks whwther this token is expired.
-func isTokenExpired(token string) bool {
+// IsTokenExpired checks whether this token is expired. Tokens which
+// cannot be parsed are considered expired.
+func IsTokenExpired(token string) bool {
 	// Parse token
 	claims := jwtgo.MapClaims{}
 
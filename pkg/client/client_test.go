@@ -0,0 +1,112 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_IsTokenExpired(t *testing.T) {
+	testCases := []struct {
+		name     string
+		token    string
+		expected bool
+	}{
+		{
+			name:     "case 0: token expired an hour ago",
+			token:    signedToken(t, time.Now().Add(-1*time.Hour)),
+			expected: true,
+		},
+		{
+			name:     "case 1: token expires in an hour",
+			token:    signedToken(t, time.Now().Add(1*time.Hour)),
+			expected: false,
+		},
+		{
+			name:     "case 2: not a JWT",
+			token:    "abcdef",
+			expected: true,
+		},
+		{
+			name:     "case 3: empty token",
+			token:    "",
+			expected: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			expired := IsTokenExpired(tc.token)
+			if expired != tc.expected {
+				t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, expired)
+			}
+		})
+	}
+}
+
+func Test_GetToken_StaticToken(t *testing.T) {
+	c, err := NewWithToken(Config{EndpointURL: "https://api.example.com"}, "giantswarm", "static-token")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	token, err := c.GetToken()
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if token != "static-token" {
+		t.Fatalf("expected static-token, got %q", token)
+	}
+}
+
+func signedToken(t *testing.T, expiresAt time.Time) string {
+	claims := jwtgo.MapClaims{
+		"exp": expiresAt.Unix(),
+	}
+	signed, err := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	return signed
+}
+
+func Test_DoRawRequest_ObserveRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	var operations []string
+	var statuses []int
+	config := Config{
+		EndpointURL: server.URL,
+		ObserveRequest: func(operation string, status int, duration time.Duration) {
+			operations = append(operations, operation)
+			statuses = append(statuses, status)
+		},
+	}
+	c, err := NewWithToken(config, "giantswarm", "static-token")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	_, _, err = c.DoRawRequest(http.MethodPost, "/v5/clusters/", []byte("{}"))
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	server.Close()
+	_, _, err = c.DoRawRequest(http.MethodPost, "/v5/clusters/", []byte("{}"))
+	if err == nil {
+		t.Fatalf("error == nil, want non-nil")
+	}
+
+	if !cmp.Equal(operations, []string{"rawPost", "rawPost"}) || !cmp.Equal(statuses, []int{http.StatusCreated, 0}) {
+		t.Fatalf("observed operations %v with statuses %v, want rawPost twice with 201 and 0", operations, statuses)
+	}
+}
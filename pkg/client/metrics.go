@@ -0,0 +1,50 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime"
+)
+
+// instrumentedTransport reports the latency and response status of every
+// operation of the generated client to observe.
+type instrumentedTransport struct {
+	transport     runtime.ClientTransport
+	httpTransport http.RoundTripper
+	observe       func(operation string, status int, duration time.Duration)
+}
+
+// Submit submits the operation using an HTTP client which captures the
+// response status.
+func (t *instrumentedTransport) Submit(operation *runtime.ClientOperation) (interface{}, error) {
+	var statusCode int
+	operation.Client = &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := t.httpTransport.RoundTrip(req)
+			if err == nil {
+				statusCode = resp.StatusCode
+			}
+			return resp, err
+		}),
+	}
+
+	start := time.Now()
+	result, err := t.transport.Submit(operation)
+	t.observe(operation.ID, statusCode, time.Since(start))
+
+	return result, err
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// rawOperation returns the operation label of requests sent by DoRawRequest.
+// Paths are left out, as they contain IDs.
+func rawOperation(method string) string {
+	return "raw" + strings.Title(strings.ToLower(method))
+}
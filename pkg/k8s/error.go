@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+// invalidManifestError is used when a manifest can't be decoded.
+var invalidManifestError = &microerror.Error{
+	Kind: "invalidManifestError",
+}
+
+// IsInvalidManifest asserts invalidManifestError.
+func IsInvalidManifest(err error) bool {
+	return microerror.Cause(err) == invalidManifestError
+}
+
+// kubectlFailedError is used when kubectl can't be executed or exits with
+// a non-zero exit code.
+var kubectlFailedError = &microerror.Error{
+	Kind: "kubectlFailedError",
+}
+
+// IsKubectlFailed asserts kubectlFailedError.
+func IsKubectlFailed(err error) bool {
+	return microerror.Cause(err) == kubectlFailedError
+}
+
+// timeoutError is used when waiting for a condition takes too long.
+var timeoutError = &microerror.Error{
+	Kind: "timeoutError",
+}
+
+// IsTimeout asserts timeoutError.
+func IsTimeout(err error) bool {
+	return microerror.Cause(err) == timeoutError
+}
+
+// IsUnauthorized asserts that the API server rejected the credentials, with
+// either client. The native client returns a 401 status error, kubectl prints
+// the reason to its output.
+func IsUnauthorized(err error) bool {
+	if err == nil {
+		return false
+	}
+	if apierrors.IsUnauthorized(microerror.Cause(err)) {
+		return true
+	}
+	if IsKubectlFailed(err) {
+		return strings.Contains(err.Error(), "Unauthorized") || strings.Contains(err.Error(), "You must be logged in")
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package k8s
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func Test_IsUnauthorized(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "case 0: nil",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "case 1: native client, unauthorized",
+			err:      microerror.Mask(apierrors.NewUnauthorized("Unauthorized")),
+			expected: true,
+		},
+		{
+			name:     "case 2: native client, forbidden",
+			err:      microerror.Mask(apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", errors.New("no RBAC"))),
+			expected: false,
+		},
+		{
+			name:     "case 3: kubectl, unauthorized",
+			err:      microerror.Maskf(kubectlFailedError, "kubectl exited with code 1: exit status 1 error: You must be logged in to the server (Unauthorized)"),
+			expected: true,
+		},
+		{
+			name:     "case 4: kubectl, connection refused",
+			err:      microerror.Maskf(kubectlFailedError, "kubectl exited with code 1: exit status 1 The connection to the server localhost:8080 was refused"),
+			expected: false,
+		},
+		{
+			name:     "case 5: other error mentioning Unauthorized",
+			err:      errors.New("Unauthorized"),
+			expected: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			result := IsUnauthorized(tc.err)
+			if result != tc.expected {
+				t.Fatalf("%s: IsUnauthorized == %v, want %v", tc.name, result, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package k8s provides access to a tenant cluster's Kubernetes API, based on
+// a kubeconfig file as written by the kubeconfig package. By default the
+// native Kubernetes client is used. Alternatively kubectl can be used, which
+// then has to be available on the PATH.
+package k8s
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Interface is implemented by the native and the kubectl based clients.
+type Interface interface {
+	// ListNodes returns the nodes matching the label selector.
+	ListNodes(ctx context.Context, selector string) ([]corev1.Node, error)
+	// Apply creates or updates all resources in the given YAML or JSON
+	// manifest, which may contain multiple documents.
+	Apply(ctx context.Context, manifest []byte) error
+	// Delete deletes all resources in the given manifest. Resources which
+	// don't exist are ignored.
+	Delete(ctx context.Context, manifest []byte) error
+	// ScaleDeployment sets the number of replicas of a deployment.
+	ScaleDeployment(ctx context.Context, namespace string, name string, replicas int32) error
+	// WaitForRollout waits until all replicas of a deployment are updated
+	// and available.
+	WaitForRollout(ctx context.Context, namespace string, name string, timeout time.Duration) error
+	// CanI returns whether the user is allowed to perform the action
+	// described by the attributes, using a SelfSubjectAccessReview.
+	CanI(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error)
+	// ServedAPIVersions returns the API versions served by the cluster in
+	// the form "group/version", e.g. "policy/v1", or "v1" for the core
+	// group.
+	ServedAPIVersions(ctx context.Context) ([]string, error)
+}
+
+// Config configures a client.
+type Config struct {
+	// KubeconfigPath is the path of the kubeconfig file to use.
+	KubeconfigPath string
+	// UseKubectl selects the kubectl based client instead of the native one.
+	UseKubectl bool
+}
+
+// Factory creates a client for the given kubeconfig.
+type Factory func(kubeconfigPath string) (Interface, error)
+
+// New returns a client as configured.
+func New(config Config) (Interface, error) {
+	if config.KubeconfigPath == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.KubeconfigPath must not be empty", config)
+	}
+
+	if config.UseKubectl {
+		return newKubectlClient(config.KubeconfigPath), nil
+	}
+
+	c, err := newNativeClient(config.KubeconfigPath)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return c, nil
+}
+
+// NewFactory returns a Factory creating native clients, or kubectl based
+// clients if useKubectl is true.
+func NewFactory(useKubectl bool) Factory {
+	return func(kubeconfigPath string) (Interface, error) {
+		return New(Config{
+			KubeconfigPath: kubeconfigPath,
+			UseKubectl:     useKubectl,
+		})
+	}
+}
+
+// IsNodeReady returns true if the node has the condition Ready.
+func IsNodeReady(node corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// CountReadyNodes returns the number of ready nodes in the given list.
+func CountReadyNodes(nodes []corev1.Node) int {
+	count := 0
+	for _, n := range nodes {
+		if IsNodeReady(n) {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,162 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/shell"
+)
+
+// kubectlClient talks to the Kubernetes API by executing kubectl.
+type kubectlClient struct {
+	kubeconfigPath string
+}
+
+func newKubectlClient(kubeconfigPath string) *kubectlClient {
+	return &kubectlClient{
+		kubeconfigPath: kubeconfigPath,
+	}
+}
+
+func (c *kubectlClient) ListNodes(ctx context.Context, selector string) ([]corev1.Node, error) {
+	out, err := c.run(ctx, "get", "nodes", "--selector", selector, "--output", "json")
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var list corev1.NodeList
+	err = json.Unmarshal([]byte(out), &list)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list.Items, nil
+}
+
+func (c *kubectlClient) Apply(ctx context.Context, manifest []byte) error {
+	err := withManifestFile(manifest, func(path string) error {
+		_, err := c.run(ctx, "apply", "-f", path)
+		return err
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *kubectlClient) Delete(ctx context.Context, manifest []byte) error {
+	err := withManifestFile(manifest, func(path string) error {
+		_, err := c.run(ctx, "delete", "--ignore-not-found", "-f", path)
+		return err
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *kubectlClient) ScaleDeployment(ctx context.Context, namespace string, name string, replicas int32) error {
+	_, err := c.run(ctx, "scale", "--namespace", namespace, "--replicas", strconv.Itoa(int(replicas)), "deployment/"+name)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *kubectlClient) WaitForRollout(ctx context.Context, namespace string, name string, timeout time.Duration) error {
+	_, err := c.run(ctx, "rollout", "status", "--namespace", namespace, "--timeout", timeout.String(), "deployment/"+name)
+	if IsKubectlFailed(err) && strings.Contains(err.Error(), "timed out") {
+		return microerror.Maskf(timeoutError, "deployment %s/%s not rolled out within %s", namespace, name, timeout)
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *kubectlClient) CanI(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	review := authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+		},
+	}
+	review.APIVersion = "authorization.k8s.io/v1"
+	review.Kind = "SelfSubjectAccessReview"
+
+	manifest, err := json.Marshal(review)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	var out string
+	err = withManifestFile(manifest, func(path string) error {
+		var err error
+		out, err = c.run(ctx, "create", "-f", path, "--output", "json")
+		return err
+	})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	var result authorizationv1.SelfSubjectAccessReview
+	err = json.Unmarshal([]byte(out), &result)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return result.Status.Allowed, nil
+}
+
+func (c *kubectlClient) ServedAPIVersions(ctx context.Context) ([]string, error) {
+	out, err := c.run(ctx, "api-versions")
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return strings.Fields(out), nil
+}
+
+func (c *kubectlClient) run(ctx context.Context, args ...string) (string, error) {
+	args = append([]string{"--kubeconfig", c.kubeconfigPath}, args...)
+	out, exitCode, err := shell.RunCommand(ctx, "kubectl", []string{}, args...)
+	if err != nil {
+		return "", microerror.Maskf(kubectlFailedError, "kubectl exited with code %d: %s %s", exitCode, err.Error(), out)
+	}
+
+	return out, nil
+}
+
+// withManifestFile writes the manifest to a temporary file, calls f with
+// its path and removes the file afterwards.
+func withManifestFile(manifest []byte, f func(path string) error) error {
+	file, err := ioutil.TempFile("", "uat-manifest-*.yaml")
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(manifest)
+	file.Close()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = f(file.Name())
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
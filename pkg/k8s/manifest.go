@@ -0,0 +1,40 @@
+package k8s
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// decodeManifest splits a YAML or JSON manifest into its objects. Empty
+// documents are skipped.
+func decodeManifest(manifest []byte) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+
+	var objects []*unstructured.Unstructured
+	for {
+		var content map[string]interface{}
+		err := decoder.Decode(&content)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, microerror.Maskf(invalidManifestError, err.Error())
+		}
+
+		if len(content) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.GetKind() == "" || obj.GetAPIVersion() == "" || obj.GetName() == "" {
+			return nil, microerror.Maskf(invalidManifestError, "object without apiVersion, kind or name in manifest")
+		}
+
+		objects = append(objects, obj)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,86 @@
+package k8s
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_decodeManifest(t *testing.T) {
+	testCases := []struct {
+		name          string
+		manifest      string
+		expectedKinds []string
+		errorMatcher  func(err error) bool
+	}{
+		{
+			name: "case 0: multiple documents",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: foo
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedKinds: []string{"Service", "Deployment"},
+		},
+		{
+			name: "case 1: empty documents are skipped",
+			manifest: `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+---
+---
+`,
+			expectedKinds: []string{"ConfigMap"},
+		},
+		{
+			name:          "case 2: JSON",
+			manifest:      `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "foo"}}`,
+			expectedKinds: []string{"Namespace"},
+		},
+		{
+			name: "case 3: object without name",
+			manifest: `apiVersion: v1
+kind: ConfigMap
+`,
+			errorMatcher: IsInvalidManifest,
+		},
+		{
+			name:         "case 4: invalid YAML",
+			manifest:     "apiVersion: v1\nkind: [",
+			errorMatcher: IsInvalidManifest,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			objects, err := decodeManifest([]byte(tc.manifest))
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("%s: error == nil, want non-nil", tc.name)
+			case !tc.errorMatcher(err):
+				t.Fatalf("%s: error == %#v, want matching", tc.name, err)
+			}
+
+			var kinds []string
+			for _, o := range objects {
+				kinds = append(kinds, o.GetKind())
+			}
+			if !cmp.Equal(kinds, tc.expectedKinds) {
+				t.Fatalf("%s: \n\n%s\n", tc.name, cmp.Diff(tc.expectedKinds, kinds))
+			}
+		})
+	}
+}
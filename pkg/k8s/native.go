@@ -0,0 +1,233 @@
+package k8s
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	// Enables users authenticating via the oidc auth provider.
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// nativeClient talks to the Kubernetes API using client-go.
+type nativeClient struct {
+	k8sClient     kubernetes.Interface
+	dynamicClient dynamic.Interface
+	mapper        meta.RESTMapper
+}
+
+func newNativeClient(kubeconfigPath string) (*nativeClient, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, err.Error())
+	}
+
+	k8sClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c := &nativeClient{
+		k8sClient:     k8sClient,
+		dynamicClient: dynamicClient,
+		mapper:        restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(k8sClient.Discovery())),
+	}
+
+	return c, nil
+}
+
+func (c *nativeClient) ListNodes(ctx context.Context, selector string) ([]corev1.Node, error) {
+	list, err := c.k8sClient.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list.Items, nil
+}
+
+func (c *nativeClient) Apply(ctx context.Context, manifest []byte) error {
+	objects, err := decodeManifest(manifest)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, obj := range objects {
+		resource, err := c.resourceFor(obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		existing, err := resource.Get(obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = resource.Create(obj, metav1.CreateOptions{})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		obj.SetResourceVersion(existing.GetResourceVersion())
+
+		// The cluster IP of a service is immutable and must be kept.
+		if obj.GetKind() == "Service" {
+			clusterIP, found, _ := unstructured.NestedString(existing.Object, "spec", "clusterIP")
+			if found {
+				err = unstructured.SetNestedField(obj.Object, clusterIP, "spec", "clusterIP")
+				if err != nil {
+					return microerror.Mask(err)
+				}
+			}
+		}
+
+		_, err = resource.Update(obj, metav1.UpdateOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+func (c *nativeClient) Delete(ctx context.Context, manifest []byte) error {
+	objects, err := decodeManifest(manifest)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, obj := range objects {
+		resource, err := c.resourceFor(obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = resource.Delete(obj.GetName(), &metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+func (c *nativeClient) ScaleDeployment(ctx context.Context, namespace string, name string, replicas int32) error {
+	deployments := c.k8sClient.AppsV1().Deployments(namespace)
+
+	scale, err := deployments.GetScale(name, metav1.GetOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	scale.Spec.Replicas = replicas
+	_, err = deployments.UpdateScale(name, scale)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *nativeClient) WaitForRollout(ctx context.Context, namespace string, name string, timeout time.Duration) error {
+	rolledOut := func() (bool, error) {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+
+		d, err := c.k8sClient.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+
+		done := d.Status.ObservedGeneration >= d.Generation &&
+			d.Status.UpdatedReplicas == replicas &&
+			d.Status.AvailableReplicas == replicas &&
+			d.Status.Replicas == replicas
+
+		return done, nil
+	}
+
+	err := wait.PollImmediate(2*time.Second, timeout, rolledOut)
+	if err == wait.ErrWaitTimeout {
+		return microerror.Maskf(timeoutError, "deployment %s/%s not rolled out within %s", namespace, name, timeout)
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *nativeClient) CanI(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+		},
+	}
+
+	result, err := c.k8sClient.AuthorizationV1().SelfSubjectAccessReviews().Create(review)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return result.Status.Allowed, nil
+}
+
+func (c *nativeClient) ServedAPIVersions(ctx context.Context) ([]string, error) {
+	groups, err := c.k8sClient.Discovery().ServerGroups()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var versions []string
+	for _, g := range groups.Groups {
+		for _, v := range g.Versions {
+			versions = append(versions, v.GroupVersion)
+		}
+	}
+
+	return versions, nil
+}
+
+// resourceFor returns the dynamic client for the object's resource. Objects
+// of namespaced resources without namespace are put into "default".
+func (c *nativeClient) resourceFor(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(metav1.NamespaceDefault)
+		}
+		return c.dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+	}
+
+	return c.dynamicClient.Resource(mapping.Resource), nil
+}
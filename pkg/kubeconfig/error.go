@@ -0,0 +1,25 @@
+package kubeconfig
+
+import "github.com/giantswarm/microerror"
+
+// invalidKubeconfigError is used when a kubeconfig can't be parsed or lacks
+// the entries we need.
+var invalidKubeconfigError = &microerror.Error{
+	Kind: "invalidKubeconfigError",
+}
+
+// IsInvalidKubeconfig asserts invalidKubeconfigError.
+func IsInvalidKubeconfig(err error) bool {
+	return microerror.Cause(err) == invalidKubeconfigError
+}
+
+// unreachableError is used when a cluster's API server can't be connected
+// to.
+var unreachableError = &microerror.Error{
+	Kind: "unreachableError",
+}
+
+// IsUnreachable asserts unreachableError.
+func IsUnreachable(err error) bool {
+	return microerror.Cause(err) == unreachableError
+}
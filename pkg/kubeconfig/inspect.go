@@ -0,0 +1,238 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/afero"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	// Enables users authenticating via the oidc auth provider.
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/certificate"
+)
+
+// CertificateInfo describes a certificate embedded in a kubeconfig.
+type CertificateInfo struct {
+	// Entry is the name of the cluster or user entry holding the certificate.
+	Entry string
+	// Field is the kubeconfig field holding the certificate, e.g.
+	// "client-certificate-data".
+	Field         string
+	Subject       string
+	Organizations []string
+	NotBefore     time.Time
+	NotAfter      time.Time
+}
+
+// Expired returns true if the certificate is not valid anymore at the given
+// time.
+func (c CertificateInfo) Expired(at time.Time) bool {
+	return at.After(c.NotAfter)
+}
+
+// ServerInfo describes the response of a cluster's API server.
+type ServerInfo struct {
+	Server     string
+	StatusCode int
+	// GitVersion is the Kubernetes version reported by the server, if the
+	// user is allowed to read it.
+	GitVersion string
+}
+
+// Load reads and parses the kubeconfig file at path. Relative certificate,
+// key and token file paths are resolved against the directory of the file.
+func Load(fileSystem afero.Fs, path string) (*clientcmdapi.Config, error) {
+	config, err := loadConfig(fileSystem, path)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, c := range config.Clusters {
+		c.LocationOfOrigin = path
+	}
+	for _, u := range config.AuthInfos {
+		u.LocationOfOrigin = path
+	}
+	err = clientcmd.ResolveLocalPaths(config)
+	if err != nil {
+		return nil, microerror.Maskf(invalidKubeconfigError, "%s: %s", path, err.Error())
+	}
+
+	return config, nil
+}
+
+// Validate checks that the current context exists and that all clusters and
+// users referenced by contexts exist. All problems found are reported in one
+// invalidKubeconfigError.
+func Validate(config *clientcmdapi.Config) error {
+	var problems []string
+
+	if config.CurrentContext == "" {
+		problems = append(problems, "current-context is not set")
+	} else if _, ok := config.Contexts[config.CurrentContext]; !ok {
+		problems = append(problems, fmt.Sprintf("current-context %q does not exist", config.CurrentContext))
+	}
+
+	for _, name := range ContextNames(config) {
+		c := config.Contexts[name]
+		if _, ok := config.Clusters[c.Cluster]; !ok {
+			problems = append(problems, fmt.Sprintf("cluster %q of context %q does not exist", c.Cluster, name))
+		}
+		if _, ok := config.AuthInfos[c.AuthInfo]; !ok {
+			problems = append(problems, fmt.Sprintf("user %q of context %q does not exist", c.AuthInfo, name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return microerror.Maskf(invalidKubeconfigError, "%s", strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// ContextNames returns the names of all contexts, sorted.
+func ContextNames(config *clientcmdapi.Config) []string {
+	var names []string
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ClusterNames returns the names of all clusters, sorted.
+func ClusterNames(config *clientcmdapi.Config) []string {
+	var names []string
+	for name := range config.Clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func userNames(config *clientcmdapi.Config) []string {
+	var names []string
+	for name := range config.AuthInfos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// CurrentCluster returns the cluster of the current context.
+func CurrentCluster(config *clientcmdapi.Config) (*clientcmdapi.Cluster, error) {
+	context, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil, microerror.Maskf(invalidKubeconfigError, "current-context %q does not exist", config.CurrentContext)
+	}
+	cluster, ok := config.Clusters[context.Cluster]
+	if !ok {
+		return nil, microerror.Maskf(invalidKubeconfigError, "cluster %q does not exist", context.Cluster)
+	}
+
+	return cluster, nil
+}
+
+// Certificates decodes all certificates embedded in clusters and users.
+func Certificates(config *clientcmdapi.Config) ([]CertificateInfo, error) {
+	var infos []CertificateInfo
+
+	for _, name := range ClusterNames(config) {
+		c := config.Clusters[name]
+		if len(c.CertificateAuthorityData) == 0 {
+			continue
+		}
+		info, err := certificateInfo(name, "certificate-authority-data", c.CertificateAuthorityData)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		infos = append(infos, info)
+	}
+
+	for _, name := range userNames(config) {
+		u := config.AuthInfos[name]
+		if len(u.ClientCertificateData) == 0 {
+			continue
+		}
+		info, err := certificateInfo(name, "client-certificate-data", u.ClientCertificateData)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// CheckServer requests the version of the API server of the current context
+// the way kubectl would, honouring CA and client certificate files,
+// insecure-skip-tls-verify, proxy settings and auth provider plugins. Any
+// HTTP response counts as reachable, even if the user isn't authorized. An
+// unreachableError is returned if the server can't be connected to within
+// the timeout.
+func CheckServer(config *clientcmdapi.Config, timeout time.Duration) (*ServerInfo, error) {
+	cluster, err := CurrentCluster(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	user := config.Contexts[config.CurrentContext].AuthInfo
+	if _, ok := config.AuthInfos[user]; !ok {
+		return nil, microerror.Maskf(invalidKubeconfigError, "user %q does not exist", user)
+	}
+
+	restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, microerror.Maskf(invalidKubeconfigError, "%s", err.Error())
+	}
+	restConfig.Timeout = timeout
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, microerror.Maskf(invalidKubeconfigError, "%s", err.Error())
+	}
+
+	info := &ServerInfo{
+		Server: cluster.Server,
+	}
+
+	version, err := discoveryClient.ServerVersion()
+	if status, ok := err.(apierrors.APIStatus); ok {
+		info.StatusCode = int(status.Status().Code)
+	} else if err != nil {
+		return nil, microerror.Maskf(unreachableError, "%s", err.Error())
+	} else {
+		info.StatusCode = http.StatusOK
+		info.GitVersion = version.GitVersion
+	}
+
+	return info, nil
+}
+
+func certificateInfo(entry, field string, pemData []byte) (CertificateInfo, error) {
+	cert, err := certificate.Parse(string(pemData))
+	if err != nil {
+		return CertificateInfo{}, microerror.Maskf(invalidKubeconfigError, "%s of %q: %s", field, entry, err.Error())
+	}
+
+	info := CertificateInfo{
+		Entry:         entry,
+		Field:         field,
+		Subject:       cert.Subject.String(),
+		Organizations: cert.Subject.Organization,
+		NotBefore:     cert.NotBefore,
+		NotAfter:      cert.NotAfter,
+	}
+
+	return info, nil
+}
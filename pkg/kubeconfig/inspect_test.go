@@ -0,0 +1,220 @@
+package kubeconfig
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func Test_Validate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		kubeconfig   string
+		errorMatcher func(error) bool
+	}{
+		{
+			name: "case 0: valid",
+			kubeconfig: `clusters:
+- name: c
+users:
+- name: u
+contexts:
+- name: ctx
+  context:
+    cluster: c
+    user: u
+current-context: ctx
+`,
+		},
+		{
+			name: "case 1: missing current context",
+			kubeconfig: `clusters:
+- name: c
+users:
+- name: u
+contexts:
+- name: ctx
+  context:
+    cluster: c
+    user: u
+current-context: other
+`,
+			errorMatcher: IsInvalidKubeconfig,
+		},
+		{
+			name: "case 2: context references missing user",
+			kubeconfig: `clusters:
+- name: c
+contexts:
+- name: ctx
+  context:
+    cluster: c
+    user: u
+current-context: ctx
+`,
+			errorMatcher: IsInvalidKubeconfig,
+		},
+		{
+			name:         "case 3: empty",
+			errorMatcher: IsInvalidKubeconfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, "config", []byte(tc.kubeconfig), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			kc, err := Load(fs, "config")
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+
+			err = Validate(kc)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("%s: error == nil, want non-nil", tc.name)
+			case !tc.errorMatcher(err):
+				t.Fatalf("%s: error == %#v, want matching", tc.name, err)
+			}
+		})
+	}
+}
+
+func Test_CertificatesAndCheckServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"gitVersion": "v1.16.9"}`))
+	}))
+	defer server.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	fs := afero.NewMemMapFs()
+	err := WriteKubeconfigFile(fs, "config", server.URL, string(caPEM), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kc, err := Load(fs, "config")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	certs, err := Certificates(kc)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if len(certs) != 1 {
+		t.Fatalf("%d certificates, want 1", len(certs))
+	}
+	if certs[0].Entry != "this-cluster" || certs[0].Field != "certificate-authority-data" {
+		t.Fatalf("certificate found in %s of %q, want certificate-authority-data of %q", certs[0].Field, certs[0].Entry, "this-cluster")
+	}
+	if !certs[0].NotAfter.Equal(server.Certificate().NotAfter) {
+		t.Fatalf("NotAfter == %s, want %s", certs[0].NotAfter, server.Certificate().NotAfter)
+	}
+
+	info, err := CheckServer(kc, 5*time.Second)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if info.StatusCode != http.StatusOK || info.GitVersion != "v1.16.9" {
+		t.Fatalf("server info == %+v, want status 200 and version v1.16.9", info)
+	}
+
+	server.Close()
+	_, err = CheckServer(kc, 5*time.Second)
+	if !IsUnreachable(err) {
+		t.Fatalf("error == %#v, want unreachableError", err)
+	}
+}
+
+func Test_Certificates_Invalid(t *testing.T) {
+	kc := clientcmdapi.NewConfig()
+	kc.Clusters["c"] = &clientcmdapi.Cluster{CertificateAuthorityData: []byte("not PEM")}
+
+	_, err := Certificates(kc)
+	if !IsInvalidKubeconfig(err) {
+		t.Fatalf("error == %#v, want invalidKubeconfigError", err)
+	}
+}
+
+func Test_CheckServer_BearerToken(t *testing.T) {
+	// The oidc auth provider only uses ID tokens which aren't expired yet.
+	idToken := "e30." + base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp": %d}`, time.Now().Add(time.Hour).Unix()))) + ".c2ln"
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		if token != "Bearer secret" && token != "Bearer "+idToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"gitVersion": "v1.16.9"}`))
+	}))
+	defer server.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	testCases := []struct {
+		name               string
+		user               *clientcmdapi.AuthInfo
+		expectedStatusCode int
+	}{
+		{
+			name:               "case 0: token",
+			user:               TokenUser("secret"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "case 1: OIDC ID token",
+			user:               OIDCUser("https://issuer.example.com/", "client", idToken, ""),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "case 2: wrong token",
+			user:               TokenUser("wrong"),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			err := WriteKubeconfigFileForUser(fs, "config", server.URL, string(caPEM), tc.user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			kc, err := Load(fs, "config")
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+
+			info, err := CheckServer(kc, 5*time.Second)
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+			if info.StatusCode != tc.expectedStatusCode {
+				t.Fatalf("%s: status code == %d, want %d", tc.name, info.StatusCode, tc.expectedStatusCode)
+			}
+		})
+	}
+}
@@ -1,98 +1,105 @@
-// Package kubeconfig contains abilities to create a simple, self-contained kubeconfig YAML file.
+// Package kubeconfig contains abilities to create a simple, self-contained kubeconfig YAML file,
+// to merge it into, or remove it from, an existing kubeconfig, and to inspect kubeconfig files.
+//
+// Users can authenticate with a client certificate, a bearer token, an OIDC auth provider or an
+// exec credential plugin.
 package kubeconfig
 
 import (
-	"encoding/base64"
+	"sort"
 
 	"github.com/giantswarm/microerror"
 	"github.com/spf13/afero"
-	"gopkg.in/yaml.v2"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
-type Kubeconfig struct {
-	APIVersion     string         `yaml:"apiVersion"`
-	Kind           string         `yaml:"kind"`
-	Clusters       []NamedCluster `yaml:"clusters"`
-	Users          []NamedUser    `yaml:"users"`
-	Contexts       []NamedContext `yaml:"contexts"`
-	CurrentContext string         `yaml:"current-context"`
-}
+// ExecAPIVersion is the client.authentication.k8s.io version used for exec
+// credential plugins.
+const ExecAPIVersion = "client.authentication.k8s.io/v1beta1"
 
-type NamedCluster struct {
-	Name    string
-	Cluster Cluster
-}
+// WriteKubeconfigFile creates a self-contained kubeconfig file with the given key pair data.
+func WriteKubeconfigFile(fileSystem afero.Fs, path, apiEndpoint, caData, certData, keyData string) error {
+	user := &clientcmdapi.AuthInfo{
+		ClientCertificateData: []byte(certData),
+		ClientKeyData:         []byte(keyData),
+	}
 
-type Cluster struct {
-	Server                   string
-	CertificateAuthorityData string `yaml:"certificate-authority-data"`
-}
+	err := WriteKubeconfigFileForUser(fileSystem, path, apiEndpoint, caData, user)
+	if err != nil {
+		return microerror.Mask(err)
+	}
 
-type NamedUser struct {
-	Name string
-	User User
+	return nil
 }
 
-type User struct {
-	ClientCertificateData string `yaml:"client-certificate-data"`
-	ClientKeyData         string `yaml:"client-key-data"`
+// TokenUser returns a user authenticating with the given bearer token.
+func TokenUser(token string) *clientcmdapi.AuthInfo {
+	return &clientcmdapi.AuthInfo{
+		Token: token,
+	}
 }
 
-type NamedContext struct {
-	Name    string
-	Context Context
-}
+// OIDCUser returns a user authenticating via the oidc auth provider with
+// the given ID token. If a refresh token is given, kubectl refreshes the ID
+// token when it expires.
+func OIDCUser(issuerURL, clientID, idToken, refreshToken string) *clientcmdapi.AuthInfo {
+	config := map[string]string{
+		"idp-issuer-url": issuerURL,
+		"client-id":      clientID,
+		"id-token":       idToken,
+	}
+	if refreshToken != "" {
+		config["refresh-token"] = refreshToken
+	}
 
-type Context struct {
-	Cluster string
-	User    string
+	return &clientcmdapi.AuthInfo{
+		AuthProvider: &clientcmdapi.AuthProviderConfig{
+			Name:   "oidc",
+			Config: config,
+		},
+	}
 }
 
-// WriteKubeconfigFile creates a self-contained kubeconfig file with the given key pair data.
-func WriteKubeconfigFile(fileSystem afero.Fs, path, apiEndpoint, caData, certData, keyData string) error {
-	caDataBase64 := base64.StdEncoding.EncodeToString([]byte(caData))
-	certDataBase64 := base64.StdEncoding.EncodeToString([]byte(certData))
-	keyDataBase64 := base64.StdEncoding.EncodeToString([]byte(keyData))
+// ExecUser returns a user getting credentials from the given exec
+// credential plugin command.
+func ExecUser(command string, args []string, env map[string]string) *clientcmdapi.AuthInfo {
+	exec := &clientcmdapi.ExecConfig{
+		APIVersion: ExecAPIVersion,
+		Command:    command,
+		Args:       args,
+	}
 
-	kc := Kubeconfig{
-		APIVersion: "v1",
-		Kind:       "Config",
-		Clusters: []NamedCluster{
-			NamedCluster{
-				Name: "this-cluster",
-				Cluster: Cluster{
-					Server:                   apiEndpoint,
-					CertificateAuthorityData: caDataBase64,
-				},
-			},
-		},
-		Users: []NamedUser{
-			NamedUser{
-				Name: "this-user",
-				User: User{
-					ClientCertificateData: certDataBase64,
-					ClientKeyData:         keyDataBase64,
-				},
-			},
-		},
-		Contexts: []NamedContext{
-			NamedContext{
-				Name: "this-context",
-				Context: Context{
-					Cluster: "this-cluster",
-					User:    "this-user",
-				},
-			},
-		},
-		CurrentContext: "this-context",
+	// Sort for reproducible files.
+	var names []string
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		exec.Env = append(exec.Env, clientcmdapi.ExecEnvVar{Name: name, Value: env[name]})
 	}
 
-	yamlBytes, err := yaml.Marshal(kc)
-	if err != nil {
-		return microerror.Mask(err)
+	return &clientcmdapi.AuthInfo{
+		Exec: exec,
+	}
+}
+
+// WriteKubeconfigFileForUser creates a self-contained kubeconfig file with
+// the given CA data and user.
+func WriteKubeconfigFileForUser(fileSystem afero.Fs, path, apiEndpoint, caData string, user *clientcmdapi.AuthInfo) error {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["this-cluster"] = &clientcmdapi.Cluster{
+		Server:                   apiEndpoint,
+		CertificateAuthorityData: []byte(caData),
+	}
+	config.AuthInfos["this-user"] = user
+	config.Contexts["this-context"] = &clientcmdapi.Context{
+		Cluster:  "this-cluster",
+		AuthInfo: "this-user",
 	}
+	config.CurrentContext = "this-context"
 
-	err = afero.WriteFile(fileSystem, path, yamlBytes, 0600)
+	err := writeConfig(fileSystem, path, config)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -0,0 +1,72 @@
+package kubeconfig
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/spf13/afero"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func Test_WriteKubeconfigFileForUser(t *testing.T) {
+	testCases := []struct {
+		name  string
+		user  *clientcmdapi.AuthInfo
+		check func(*clientcmdapi.AuthInfo) bool
+	}{
+		{
+			name: "case 0: token",
+			user: TokenUser("secret"),
+			check: func(a *clientcmdapi.AuthInfo) bool {
+				return a.Token == "secret" && a.AuthProvider == nil && a.Exec == nil && len(a.ClientCertificateData) == 0
+			},
+		},
+		{
+			name: "case 1: OIDC auth provider",
+			user: OIDCUser("https://issuer.example.com/", "client", "id-token", "refresh-token"),
+			check: func(a *clientcmdapi.AuthInfo) bool {
+				return a.AuthProvider != nil &&
+					a.AuthProvider.Name == "oidc" &&
+					a.AuthProvider.Config["idp-issuer-url"] == "https://issuer.example.com/" &&
+					a.AuthProvider.Config["client-id"] == "client" &&
+					a.AuthProvider.Config["id-token"] == "id-token" &&
+					a.AuthProvider.Config["refresh-token"] == "refresh-token"
+			},
+		},
+		{
+			name: "case 2: exec plugin",
+			user: ExecUser("cat", []string{"credential.json"}, map[string]string{"B": "2", "A": "1"}),
+			check: func(a *clientcmdapi.AuthInfo) bool {
+				return a.Exec != nil &&
+					a.Exec.APIVersion == ExecAPIVersion &&
+					a.Exec.Command == "cat" &&
+					len(a.Exec.Args) == 1 &&
+					len(a.Exec.Env) == 2 && a.Exec.Env[0].Name == "A" && a.Exec.Env[1].Value == "2"
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+
+			err := WriteKubeconfigFileForUser(fs, "config", "https://api.example.com", "ca", tc.user)
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+
+			// Make sure client-go understands what we wrote.
+			config, err := loadConfig(fs, "config")
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+			authInfo, ok := config.AuthInfos["this-user"]
+			if !ok {
+				t.Fatalf("%s: user %q not found", tc.name, "this-user")
+			}
+			if !tc.check(authInfo) {
+				t.Fatalf("%s: unexpected user %#v", tc.name, authInfo)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/afero"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// EntryName returns the name of the cluster, user and context entries of a
+// tenant cluster in a merged kubeconfig, e.g. "gs-ginger-a1b2c".
+func EntryName(installation, clusterID string) string {
+	return fmt.Sprintf("gs-%s-%s", installation, clusterID)
+}
+
+// MergeKubeconfigFile copies the current context of the kubeconfig file at
+// sourcePath, together with its cluster and user, into the kubeconfig file
+// at path. All three entries are named after the installation and cluster
+// ID, replacing entries left over from earlier runs. The file at path is
+// created if it doesn't exist. Its current context is only set if it had
+// none before. Returns the name of the merged context.
+func MergeKubeconfigFile(fileSystem afero.Fs, path, sourcePath, installation, clusterID string) (string, error) {
+	source, err := loadConfig(fileSystem, sourcePath)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	sourceContext, ok := source.Contexts[source.CurrentContext]
+	if !ok {
+		return "", microerror.Maskf(invalidKubeconfigError, "current context %q not found in %s", source.CurrentContext, sourcePath)
+	}
+	sourceCluster, ok := source.Clusters[sourceContext.Cluster]
+	if !ok {
+		return "", microerror.Maskf(invalidKubeconfigError, "cluster %q not found in %s", sourceContext.Cluster, sourcePath)
+	}
+	sourceUser, ok := source.AuthInfos[sourceContext.AuthInfo]
+	if !ok {
+		return "", microerror.Maskf(invalidKubeconfigError, "user %q not found in %s", sourceContext.AuthInfo, sourcePath)
+	}
+
+	target, err := loadConfigIfExists(fileSystem, path)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	name := EntryName(installation, clusterID)
+
+	context := sourceContext.DeepCopy()
+	context.Cluster = name
+	context.AuthInfo = name
+
+	target.Clusters[name] = sourceCluster.DeepCopy()
+	target.AuthInfos[name] = sourceUser.DeepCopy()
+	target.Contexts[name] = context
+	if target.CurrentContext == "" {
+		target.CurrentContext = name
+	}
+
+	err = writeConfig(fileSystem, path, target)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return name, nil
+}
+
+// RemoveFromKubeconfigFile removes the cluster, user and context entries of
+// the given tenant cluster, as added by MergeKubeconfigFile, from the
+// kubeconfig file at path. If the removed context was the current one, the
+// current context is unset. A missing file or missing entries are no error.
+func RemoveFromKubeconfigFile(fileSystem afero.Fs, path, installation, clusterID string) error {
+	exists, err := afero.Exists(fileSystem, path)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if !exists {
+		return nil
+	}
+
+	config, err := loadConfig(fileSystem, path)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	name := EntryName(installation, clusterID)
+
+	delete(config.Clusters, name)
+	delete(config.AuthInfos, name)
+	delete(config.Contexts, name)
+	if config.CurrentContext == name {
+		config.CurrentContext = ""
+	}
+
+	err = writeConfig(fileSystem, path, config)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func loadConfig(fileSystem afero.Fs, path string) (*clientcmdapi.Config, error) {
+	data, err := afero.ReadFile(fileSystem, path)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, microerror.Maskf(invalidKubeconfigError, "%s: %s", path, err.Error())
+	}
+
+	return config, nil
+}
+
+func loadConfigIfExists(fileSystem afero.Fs, path string) (*clientcmdapi.Config, error) {
+	config, err := loadConfig(fileSystem, path)
+	if os.IsNotExist(microerror.Cause(err)) {
+		return clientcmdapi.NewConfig(), nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return config, nil
+}
+
+func writeConfig(fileSystem afero.Fs, path string, config *clientcmdapi.Config) error {
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = afero.WriteFile(fileSystem, path, data, 0600)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package kubeconfig
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+const existingKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: other
+  cluster:
+    server: https://other.example.com
+- name: gs-ginger-a1b2c
+  cluster:
+    server: https://stale.example.com
+users:
+- name: other
+  user:
+    token: secret
+- name: gs-ginger-a1b2c
+  user:
+    token: stale
+contexts:
+- name: other
+  context:
+    cluster: other
+    user: other
+    namespace: kube-system
+- name: gs-ginger-a1b2c
+  context:
+    cluster: gs-ginger-a1b2c
+    user: gs-ginger-a1b2c
+current-context: other
+`
+
+func Test_MergeKubeconfigFile(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		existing               string
+		expectedClusters       int
+		expectedCurrentContext string
+	}{
+		{
+			name:                   "case 0: merge into missing file",
+			expectedClusters:       1,
+			expectedCurrentContext: "gs-ginger-a1b2c",
+		},
+		{
+			name:                   "case 1: merge into existing file, replacing stale entries",
+			existing:               existingKubeconfig,
+			expectedClusters:       2,
+			expectedCurrentContext: "other",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+
+			err := WriteKubeconfigFile(fs, "source.yaml", "https://api.a1b2c.example.com", "ca", "cert", "key")
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+			if tc.existing != "" {
+				err = afero.WriteFile(fs, "config", []byte(tc.existing), 0600)
+				if err != nil {
+					t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+				}
+			}
+
+			name, err := MergeKubeconfigFile(fs, "config", "source.yaml", "ginger", "a1b2c")
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+			if name != "gs-ginger-a1b2c" {
+				t.Fatalf("%s: name == %q, want %q", tc.name, name, "gs-ginger-a1b2c")
+			}
+
+			merged, err := loadConfig(fs, "config")
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+			if len(merged.Clusters) != tc.expectedClusters {
+				t.Fatalf("%s: %d clusters, want %d", tc.name, len(merged.Clusters), tc.expectedClusters)
+			}
+			if merged.CurrentContext != tc.expectedCurrentContext {
+				t.Fatalf("%s: current context == %q, want %q", tc.name, merged.CurrentContext, tc.expectedCurrentContext)
+			}
+			if merged.Clusters[name].Server != "https://api.a1b2c.example.com" {
+				t.Fatalf("%s: server == %q, want the merged one", tc.name, merged.Clusters[name].Server)
+			}
+			if string(merged.AuthInfos[name].ClientKeyData) != "key" || merged.AuthInfos[name].Token != "" {
+				t.Fatalf("%s: user %q was not replaced", tc.name, name)
+			}
+			if merged.Contexts[name].Cluster != name || merged.Contexts[name].AuthInfo != name {
+				t.Fatalf("%s: context %q doesn't reference the merged entries", tc.name, name)
+			}
+
+			err = RemoveFromKubeconfigFile(fs, "config", "ginger", "a1b2c")
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+
+			cleaned, err := loadConfig(fs, "config")
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+			if len(cleaned.Clusters) != tc.expectedClusters-1 || len(cleaned.AuthInfos) != tc.expectedClusters-1 || len(cleaned.Contexts) != tc.expectedClusters-1 {
+				t.Fatalf("%s: entries of %q were not removed", tc.name, name)
+			}
+			if cleaned.CurrentContext == name {
+				t.Fatalf("%s: current context still points to removed context %q", tc.name, name)
+			}
+			if tc.existing != "" && cleaned.Contexts["other"].Namespace != "kube-system" {
+				t.Fatalf("%s: unrelated context was modified", tc.name)
+			}
+		})
+	}
+}
+
+func Test_RemoveFromKubeconfigFile_Missing(t *testing.T) {
+	err := RemoveFromKubeconfigFile(afero.NewMemMapFs(), "config", "ginger", "a1b2c")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+}
@@ -0,0 +1,48 @@
+package load
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var alreadyStartedError = &microerror.Error{
+	Kind: "alreadyStartedError",
+}
+
+// IsAlreadyStarted asserts alreadyStartedError.
+func IsAlreadyStarted(err error) bool {
+	return microerror.Cause(err) == alreadyStartedError
+}
+
+var notStartedError = &microerror.Error{
+	Kind: "notStartedError",
+}
+
+// IsNotStarted asserts notStartedError.
+func IsNotStarted(err error) bool {
+	return microerror.Cause(err) == notStartedError
+}
+
+var sloViolatedError = &microerror.Error{
+	Kind: "sloViolatedError",
+}
+
+// IsSLOViolated asserts sloViolatedError.
+func IsSLOViolated(err error) bool {
+	return microerror.Cause(err) == sloViolatedError
+}
+
+var invalidProfileError = &microerror.Error{
+	Kind: "invalidProfileError",
+}
+
+// IsInvalidProfile asserts invalidProfileError.
+func IsInvalidProfile(err error) bool {
+	return microerror.Cause(err) == invalidProfileError
+}
@@ -0,0 +1,178 @@
+package load
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Results holds the statistics of a run.
+type Results struct {
+	// Intervals holds the statistics of every interval completed so far.
+	Intervals []Stats
+	// Overall holds the statistics of all requests so far.
+	Overall Stats
+}
+
+// Generator generates load in the background and collects statistics.
+type Generator struct {
+	config Config
+
+	mutex     sync.Mutex
+	collector *Collector
+	cancel    context.CancelFunc
+	done      chan struct{}
+	sinkErr   error
+}
+
+// New returns a generator for the given configuration.
+func New(config Config) (*Generator, error) {
+	err := config.validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if config.Interval == 0 {
+		config.Interval = defaultInterval
+	}
+
+	g := &Generator{
+		config: config,
+	}
+
+	return g, nil
+}
+
+// Start starts generating load in the background. Load is generated until
+// Stop is called or ctx is done. A generator can only be started once.
+func (g *Generator) Start(ctx context.Context) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.done != nil {
+		return microerror.Mask(alreadyStartedError)
+	}
+
+	ctx, g.cancel = context.WithCancel(ctx)
+	g.collector = NewCollector()
+	g.done = make(chan struct{})
+	if shape := g.config.shape(); shape != nil {
+		start := time.Now()
+		g.collector.targetRate = func(at time.Time) float64 {
+			return math.Max(shape.Rate(at.Sub(start)), 0)
+		}
+	}
+
+	go g.run(ctx)
+
+	return nil
+}
+
+// Stop stops generating load and waits until the final statistics have been
+// passed to the sinks. It returns the first error returned by a sink.
+func (g *Generator) Stop() error {
+	g.mutex.Lock()
+	cancel, done := g.cancel, g.done
+	g.mutex.Unlock()
+
+	if done == nil {
+		return microerror.Mask(notStartedError)
+	}
+
+	cancel()
+	<-done
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.sinkErr != nil {
+		return microerror.Mask(g.sinkErr)
+	}
+
+	return nil
+}
+
+// Done returns a channel which is closed when the generator has stopped,
+// either because Stop was called or because the context passed to Start is
+// done.
+func (g *Generator) Done() <-chan struct{} {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.done
+}
+
+// Results returns the statistics collected so far. It can be called while
+// load is generated.
+func (g *Generator) Results() Results {
+	g.mutex.Lock()
+	collector := g.collector
+	g.mutex.Unlock()
+
+	if collector == nil {
+		return Results{}
+	}
+
+	return Results{
+		Intervals: collector.Intervals(),
+		Overall:   collector.Overall(),
+	}
+}
+
+// StartWindow starts collecting statistics for a period of time of interest,
+// e.g. while a step of a test runs. The generator must have been started.
+func (g *Generator) StartWindow() (*Window, error) {
+	g.mutex.Lock()
+	collector := g.collector
+	g.mutex.Unlock()
+
+	if collector == nil {
+		return nil, microerror.Mask(notStartedError)
+	}
+
+	return collector.StartWindow(), nil
+}
+
+func (g *Generator) run(ctx context.Context) {
+	defer close(g.done)
+
+	loadDone := make(chan struct{})
+	go func() {
+		defer close(loadDone)
+		// The config has been validated and the collector's record function
+		// is set, so there is no error to handle.
+		_ = Run(ctx, g.config, g.collector.Record)
+	}()
+
+	ticker := time.NewTicker(g.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.write(PeriodInterval, g.collector.Flush())
+		case <-loadDone:
+			// Report the last, incomplete interval as well.
+			g.write(PeriodInterval, g.collector.Flush())
+			g.write(PeriodOverall, g.collector.Overall())
+			return
+		}
+	}
+}
+
+// write passes the statistics to all sinks and remembers the first error.
+func (g *Generator) write(period Period, stats Stats) {
+	for _, s := range g.config.Sinks {
+		err := s.Write(period, stats)
+		if err != nil {
+			g.mutex.Lock()
+			if g.sinkErr == nil {
+				g.sinkErr = err
+			}
+			g.mutex.Unlock()
+		}
+	}
+}
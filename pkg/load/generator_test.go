@@ -0,0 +1,117 @@
+package load
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func Test_Generator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	var mutex sync.Mutex
+	var intervals, overalls []Stats
+	callback := SinkFunc(func(period Period, stats Stats) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch period {
+		case PeriodInterval:
+			intervals = append(intervals, stats)
+		case PeriodOverall:
+			overalls = append(overalls, stats)
+		}
+		return nil
+	})
+
+	var jsonOutput bytes.Buffer
+	fs := afero.NewMemMapFs()
+
+	g, err := New(Config{
+		URL:      server.URL,
+		Workers:  2,
+		Rate:     100,
+		Interval: 200 * time.Millisecond,
+		Sinks:    []Sink{callback, NewJSONSink(&jsonOutput), NewFileSink(fs, "load.log")},
+	})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	err = g.Stop()
+	if !IsNotStarted(err) {
+		t.Fatalf("error == %#v, want notStartedError", err)
+	}
+
+	err = g.Start(context.Background())
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	err = g.Start(context.Background())
+	if !IsAlreadyStarted(err) {
+		t.Fatalf("error == %#v, want alreadyStartedError", err)
+	}
+
+	time.Sleep(time.Second)
+
+	err = g.Stop()
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	results := g.Results()
+	if len(results.Intervals) != len(intervals) {
+		t.Fatalf("%d intervals in results, %d written to sink", len(results.Intervals), len(intervals))
+	}
+	if len(intervals) < 5 || len(intervals) > 6 {
+		t.Fatalf("%d intervals, want 5 to 6", len(intervals))
+	}
+	if len(overalls) != 1 {
+		t.Fatalf("%d overall stats, want 1", len(overalls))
+	}
+	if results.Overall.Requests < 90 || results.Overall.Requests > 110 {
+		t.Fatalf("%d requests, want 90 to 110", results.Overall.Requests)
+	}
+	if results.Overall.Failures != 0 {
+		t.Fatalf("%d failures, want 0", results.Overall.Failures)
+	}
+
+	lines := strings.Split(strings.TrimSpace(jsonOutput.String()), "\n")
+	if len(lines) != len(intervals)+1 {
+		t.Fatalf("%d JSON lines, want %d", len(lines), len(intervals)+1)
+	}
+	var record jsonRecord
+	err = json.Unmarshal([]byte(lines[len(lines)-1]), &record)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if record.Period != PeriodOverall || record.Requests != results.Overall.Requests || record.StatusCodes[http.StatusOK] != record.Requests {
+		t.Fatalf("last JSON record == %+v, want overall stats", record)
+	}
+
+	logData, err := afero.ReadFile(fs, "load.log")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if strings.Count(string(logData), "\n") != len(intervals)+1 {
+		t.Fatalf("log file has %d lines, want %d", strings.Count(string(logData), "\n"), len(intervals)+1)
+	}
+}
+
+func Test_New_InvalidConfig(t *testing.T) {
+	_, err := New(Config{URL: "http://localhost", Interval: -1})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("error == %#v, want invalidConfigError", err)
+	}
+}
@@ -0,0 +1,120 @@
+package load
+
+import (
+	"math/bits"
+	"time"
+)
+
+// subBucketBits defines the precision of the histogram. Each power of two
+// range is split into 2^subBucketBits buckets, so recorded values are off by
+// less than 1/2^subBucketBits, i.e. 0.8%.
+const subBucketBits = 7
+
+const subBuckets = 1 << subBucketBits
+
+// Histogram records latencies in microseconds, in the fashion of an HDR
+// histogram: bucket sizes grow with the value, so that the relative error
+// stays constant while the memory needed stays small. The zero value is
+// ready to use. A Histogram is not safe for concurrent use.
+type Histogram struct {
+	counts []int64
+	count  int64
+	sum    time.Duration
+	max    time.Duration
+}
+
+// Record adds a latency to the histogram. Negative values are recorded as
+// zero.
+func (h *Histogram) Record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	i := bucketIndex(uint64(d / time.Microsecond))
+	if i >= len(h.counts) {
+		counts := make([]int64, i+1)
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+
+	h.counts[i]++
+	h.count++
+	h.sum += d
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// Count returns the number of recorded values.
+func (h *Histogram) Count() int64 {
+	return h.count
+}
+
+// Max returns the highest recorded value.
+func (h *Histogram) Max() time.Duration {
+	return h.max
+}
+
+// Mean returns the average of the recorded values.
+func (h *Histogram) Mean() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	return h.sum / time.Duration(h.count)
+}
+
+// Quantile returns the value below which the given fraction of the recorded
+// values lies, e.g. 0.99 for the 99th percentile. The result is the upper
+// bound of the bucket holding the quantile, but never more than Max.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	// The rank of the value we are looking for, counting from 1.
+	rank := int64(q*float64(h.count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			d := time.Duration(bucketUpperBound(i)) * time.Microsecond
+			if d > h.max {
+				d = h.max
+			}
+			return d
+		}
+	}
+
+	return h.max
+}
+
+// bucketIndex returns the index of the bucket holding the value. Values up to
+// 2*subBuckets get a bucket of their own. Above, every power of two range is
+// split into subBuckets buckets.
+func bucketIndex(v uint64) int {
+	if v < 2*subBuckets {
+		return int(v)
+	}
+
+	shift := bits.Len64(v) - (subBucketBits + 1)
+
+	return shift*subBuckets + int(v>>uint(shift))
+}
+
+// bucketUpperBound returns the highest value stored in the bucket with the
+// given index.
+func bucketUpperBound(i int) uint64 {
+	if i < 2*subBuckets {
+		return uint64(i)
+	}
+
+	shift := i/subBuckets - 1
+	lower := uint64(i-shift*subBuckets) << uint(shift)
+
+	return lower + (1 << uint(shift)) - 1
+}
@@ -0,0 +1,94 @@
+package load
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_Histogram_Quantile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   []time.Duration
+		quantile float64
+		expected time.Duration
+	}{
+		{
+			name:     "case 0: empty",
+			quantile: 0.5,
+			expected: 0,
+		},
+		{
+			name:     "case 1: small values are exact",
+			values:   []time.Duration{10 * time.Microsecond, 20 * time.Microsecond, 30 * time.Microsecond},
+			quantile: 0.5,
+			expected: 20 * time.Microsecond,
+		},
+		{
+			name:     "case 2: quantile is capped at max",
+			values:   []time.Duration{1234567 * time.Microsecond},
+			quantile: 0.99,
+			expected: 1234567 * time.Microsecond,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var h Histogram
+			for _, v := range tc.values {
+				h.Record(v)
+			}
+
+			q := h.Quantile(tc.quantile)
+			if q != tc.expected {
+				t.Fatalf("%s: quantile == %s, want %s", tc.name, q, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_Histogram_Precision(t *testing.T) {
+	var h Histogram
+	// 1ms to 10s in 1ms steps.
+	for i := 1; i <= 10000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	if h.Count() != 10000 {
+		t.Fatalf("count == %d, want 10000", h.Count())
+	}
+	if h.Max() != 10*time.Second {
+		t.Fatalf("max == %s, want 10s", h.Max())
+	}
+
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		expected := time.Duration(q*10000) * time.Millisecond
+		got := h.Quantile(q)
+		diff := got - expected
+		if diff < 0 {
+			diff = -diff
+		}
+		if float64(diff) > float64(expected)/subBuckets {
+			t.Fatalf("quantile %.2f == %s, want %s within 1/%d", q, got, expected, subBuckets)
+		}
+	}
+}
+
+func Test_bucketIndex(t *testing.T) {
+	previous := -1
+	for _, v := range []uint64{0, 1, 255, 256, 257, 258, 511, 512, 1 << 20, 1<<20 + 12345, 1 << 40} {
+		i := bucketIndex(v)
+		if i < previous {
+			t.Fatalf("bucketIndex(%d) == %d, want >= %d", v, i, previous)
+		}
+		previous = i
+
+		upper := bucketUpperBound(i)
+		if upper < v {
+			t.Fatalf("bucketUpperBound(%d) == %d, want >= %d", i, upper, v)
+		}
+		if bucketIndex(upper) != i {
+			t.Fatalf("upper bound %d of bucket %d is in bucket %d", upper, i, bucketIndex(upper))
+		}
+	}
+}
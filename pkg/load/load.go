@@ -1,59 +1,326 @@
+// Package load generates HTTP load on an endpoint, e.g. the ingress of a test
+// app, and collects statistics about the responses.
 package load
 
 import (
+	"context"
+	"io"
 	"io/ioutil"
-	"log"
+	"math/rand"
 	"net/http"
-	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/giantswarm/microerror"
 )
 
-// ProduceLoad creates load on an endpoint.
-// It finishes when durationLimit or requestLimit is reached, whatever comes earlier.
-// Stats are logged to a file every 10 seconds.
-func ProduceLoad(endpointURL string, durationLimit time.Duration, requestLimit int) {
-	startTime := time.Now()
-	lastOutput := time.Now()
-	endTime := startTime.Add(durationLimit)
+const (
+	defaultInterval = 10 * time.Second
+	defaultTimeout  = 10 * time.Second
+
+	// shapeResolution is the longest time the scheduler waits before
+	// checking the target rate again, so that changes take effect even while
+	// the rate is low.
+	shapeResolution = 100 * time.Millisecond
+)
 
-	var successCount int64
-	var errorCount int64
+// Config configures load generation.
+type Config struct {
+	// URL is requested with GET, unless Requests are set. Then the paths of
+	// the requests are resolved against it.
+	URL string
+	// Requests are templates of the requests to send, each picked randomly
+	// according to its weight. Optional.
+	Requests []Request
+	// NewConnections makes every request use a new connection. By default
+	// connections are kept alive. Ignored if HTTPClient is set.
+	NewConnections bool
+	// Workers is the number of requests in flight at most. Defaults to 1.
+	Workers int
+	// Rate is the target number of requests per second over all workers.
+	// If zero, every worker sends its next request as soon as the previous
+	// one is done (closed loop). Otherwise requests are started at this rate,
+	// independent of response times (open loop). A request is dropped if all
+	// workers stay busy until the next one is due.
+	Rate float64
+	// Shape changes the target rate over time in open loop mode. Must not be
+	// set together with Rate.
+	Shape Shape
+	// Timeout limits each request. Defaults to 10 seconds.
+	Timeout time.Duration
+	// HTTPClient sends the requests. Defaults to a client keeping one idle
+	// connection per worker.
+	HTTPClient *http.Client
+	// Observe is called with the result of every request, like record, e.g.
+	// to export metrics. It is called concurrently. Optional.
+	Observe func(Result)
+
+	// Interval is the period of time statistics are collected for before
+	// they are passed to the sinks. Only used by the Generator. Defaults to
+	// 10 seconds.
+	Interval time.Duration
+	// Sinks receive the statistics of every interval and of the whole run.
+	// Only used by the Generator.
+	Sinks []Sink
+}
 
-	f, err := os.OpenFile("load.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+func (c Config) validate() error {
+	if c.URL == "" {
+		return microerror.Maskf(invalidConfigError, "%T.URL must not be empty", c)
+	}
+	if c.Workers < 0 {
+		return microerror.Maskf(invalidConfigError, "%T.Workers must not be negative", c)
+	}
+	if c.Rate < 0 {
+		return microerror.Maskf(invalidConfigError, "%T.Rate must not be negative", c)
+	}
+	if c.Rate > 0 && c.Shape != nil {
+		return microerror.Maskf(invalidConfigError, "%T.Rate and %T.Shape must not both be set", c, c)
+	}
+	if c.Timeout < 0 {
+		return microerror.Maskf(invalidConfigError, "%T.Timeout must not be negative", c)
+	}
+	if c.Interval < 0 {
+		return microerror.Maskf(invalidConfigError, "%T.Interval must not be negative", c)
+	}
+	problems := requestProblems(c.Requests)
+	if len(problems) > 0 {
+		return microerror.Maskf(invalidConfigError, "%T.Requests: %s", c, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// shape returns the shape of the target rate, or nil in closed loop mode.
+func (c Config) shape() Shape {
+	if c.Shape != nil {
+		return c.Shape
+	}
+	if c.Rate > 0 {
+		return Constant(c.Rate)
+	}
+
+	return nil
+}
+
+// Result is the outcome of a single request.
+type Result struct {
+	Start      time.Time
+	Latency    time.Duration
+	StatusCode int
+	// Err is set if no complete response has been received.
+	Err error
+	// ExpectedStatus is the status code of a successful response. If zero,
+	// every response below 500 is successful.
+	ExpectedStatus int
+	// Dropped is true if the request was due in open loop mode, but not sent
+	// because all workers were busy until the next one was due.
+	Dropped bool
+}
+
+// Failed returns true if the request was sent, but didn't get a response or
+// got a server error response or not the expected status code.
+func (r Result) Failed() bool {
+	if r.Dropped {
+		return false
+	}
+	if r.Err != nil {
+		return true
+	}
+	if r.ExpectedStatus != 0 {
+		return r.StatusCode != r.ExpectedStatus
+	}
+
+	return r.StatusCode >= 500
+}
+
+// Run sends requests as configured until ctx is done and passes the result of
+// every request to record. record is called concurrently by all workers.
+func Run(ctx context.Context, config Config, record func(Result)) error {
+	err := config.validate()
 	if err != nil {
-		log.Fatalf("error opening file: %v", err)
+		return microerror.Mask(err)
+	}
+	if record == nil {
+		return microerror.Maskf(invalidConfigError, "record must not be empty")
 	}
-	defer f.Close()
-	log.SetOutput(f)
 
-	for i := 0; i < requestLimit; i++ {
-		if time.Now().After(endTime) {
+	if config.Observe != nil {
+		collect := record
+		record = func(r Result) {
+			config.Observe(r)
+			collect(r)
+		}
+	}
+	if config.Workers == 0 {
+		config.Workers = 1
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				MaxIdleConnsPerHost: config.Workers,
+				DisableKeepAlives:   config.NewConnections,
+			},
+		}
+	}
+
+	targets, err := newPicker(config)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// In closed loop mode workers don't wait for anything but ctx, so jobs
+	// stays nil.
+	var jobs chan time.Time
+	shape := config.shape()
+	if shape != nil {
+		jobs = make(chan time.Time)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < config.Workers; i++ {
+		wg.Add(1)
+		rnd := rand.New(rand.NewSource(time.Now().UnixNano() + int64(i)))
+		go func() {
+			defer wg.Done()
+			worker(ctx, config, targets, rnd, jobs, record)
+		}()
+	}
+
+	if jobs != nil {
+		schedule(ctx, shape, jobs, record)
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// schedule hands out jobs to free workers at the target rate of the shape
+// until ctx is done, then closes jobs.
+func schedule(ctx context.Context, shape Shape, jobs chan<- time.Time, record func(Result)) {
+	defer close(jobs)
+
+	start := time.Now()
+	next := start
+	// credit accumulates fractions of requests while the rate is so low
+	// that less than one request is due per shapeResolution. Starting with
+	// one lets the first request go out right away.
+	credit := 1.0
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
 			return
+		case <-timer.C:
 		}
 
-		interval := time.Now().Sub(lastOutput)
-		if interval >= 10*time.Second {
-			numRequests := errorCount + successCount
-			duration := interval.Seconds() / float64(numRequests)
-			log.Printf("numRequests %d, successCount %d, errorCount %d, error rate: %.5f, average request duration: %.5f Sec", numRequests, successCount, errorCount, float64(errorCount)/float64(numRequests), duration)
-			successCount = 0
-			errorCount = 0
-			lastOutput = time.Now()
+		// Keep the schedule independent of how long handing out the job
+		// took.
+		due := next
+		rate := shape.Rate(due.Sub(start))
+		send := false
+		if rate > 0 {
+			interval := time.Duration(float64(time.Second) / rate)
+			if interval <= shapeResolution {
+				next = next.Add(interval)
+				send = true
+			} else {
+				next = next.Add(shapeResolution)
+				credit += rate * shapeResolution.Seconds()
+				if credit >= 1 {
+					credit--
+					send = true
+				}
+			}
+		} else {
+			next = next.Add(shapeResolution)
 		}
 
-		resp, err := http.Get(endpointURL)
-		if err != nil {
-			errorCount++
-			continue
+		if send {
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- due:
+			case <-time.After(time.Until(next)):
+				record(Result{Start: due, Dropped: true})
+			}
+		}
+
+		timer.Reset(time.Until(next))
+	}
+}
+
+func worker(ctx context.Context, config Config, targets *picker, rnd *rand.Rand, jobs <-chan time.Time, record func(Result)) {
+	for {
+		if jobs == nil {
+			if ctx.Err() != nil {
+				return
+			}
+		} else {
+			_, ok := <-jobs
+			if !ok {
+				return
+			}
 		}
 
-		defer resp.Body.Close()
-		_, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			errorCount++
+		result := send(ctx, config, targets.pick(rnd))
+		// Requests cancelled because load generation stopped don't count.
+		if ctx.Err() != nil {
+			return
+		}
+		record(result)
+	}
+}
+
+// send sends a single request and reads the full response body.
+func send(ctx context.Context, config Config, t target) Result {
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	result := Result{
+		Start:          time.Now(),
+		ExpectedStatus: t.request.ExpectedStatus,
+	}
+
+	var body io.Reader
+	if t.request.Body != "" {
+		body = strings.NewReader(t.request.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, t.request.Method, t.url, body)
+	if err != nil {
+		result.Err = microerror.Mask(err)
+		return result
+	}
+	for name, value := range t.request.Headers {
+		if http.CanonicalHeaderKey(name) == "Host" {
+			req.Host = value
 			continue
 		}
+		req.Header.Set(name, value)
+	}
 
-		successCount++
+	resp, err := config.HTTPClient.Do(req)
+	if err != nil {
+		result.Latency = time.Since(result.Start)
+		result.Err = microerror.Mask(err)
+		return result
 	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	result.Latency = time.Since(result.Start)
+	result.StatusCode = resp.StatusCode
+	if err != nil {
+		result.Err = microerror.Mask(err)
+	}
+
+	return result
 }
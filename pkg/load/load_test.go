@@ -1,19 +1,119 @@
 package load
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
 
-func Test_ProduceLoad(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(500 * time.Millisecond)
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`OK`))
-	}))
-	defer server.Close()
+func Test_Run(t *testing.T) {
+	testCases := []struct {
+		name        string
+		delay       time.Duration
+		config      Config
+		duration    time.Duration
+		minRequests int
+		maxRequests int
+		minFailed   int
+		maxFailed   int
+		minDropped  int
+		maxDropped  int
+	}{
+		{
+			name:        "case 0: closed loop, requests are limited by response time and workers",
+			delay:       100 * time.Millisecond,
+			config:      Config{Workers: 4},
+			duration:    time.Second,
+			minRequests: 30,
+			maxRequests: 44,
+		},
+		{
+			name:        "case 1: open loop, requests follow the rate",
+			delay:       10 * time.Millisecond,
+			config:      Config{Workers: 4, Rate: 50},
+			duration:    time.Second,
+			minRequests: 45,
+			maxRequests: 55,
+		},
+		{
+			name:        "case 2: open loop, requests due while workers are busy are dropped",
+			delay:       200 * time.Millisecond,
+			config:      Config{Workers: 1, Rate: 20},
+			duration:    time.Second,
+			minRequests: 4,
+			maxRequests: 6,
+			minDropped:  14,
+			maxDropped:  17,
+		},
+		{
+			name:        "case 3: requests time out",
+			delay:       200 * time.Millisecond,
+			config:      Config{Workers: 2, Timeout: 50 * time.Millisecond},
+			duration:    time.Second,
+			minRequests: 30,
+			maxRequests: 42,
+			minFailed:   30,
+			maxFailed:   42,
+		},
+	}
 
-	ProduceLoad(server.URL, 15*time.Second, 1_000_000_000)
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(tc.delay):
+				case <-r.Context().Done():
+				}
+				_, _ = w.Write([]byte(`OK`))
+			}))
+			defer server.Close()
+
+			var mutex sync.Mutex
+			var requests, failed, dropped int
+			record := func(r Result) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if r.Dropped {
+					dropped++
+					return
+				}
+				requests++
+				if r.Failed() {
+					failed++
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tc.duration)
+			defer cancel()
+
+			config := tc.config
+			config.URL = server.URL
+			err := Run(ctx, config, record)
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+
+			if requests < tc.minRequests || requests > tc.maxRequests {
+				t.Fatalf("%s: %d requests, want %d to %d", tc.name, requests, tc.minRequests, tc.maxRequests)
+			}
+			if failed < tc.minFailed || failed > tc.maxFailed {
+				t.Fatalf("%s: %d failed requests, want %d to %d", tc.name, failed, tc.minFailed, tc.maxFailed)
+			}
+			if dropped < tc.minDropped || dropped > tc.maxDropped {
+				t.Fatalf("%s: %d dropped requests, want %d to %d", tc.name, dropped, tc.minDropped, tc.maxDropped)
+			}
+		})
+	}
+}
+
+func Test_Run_InvalidConfig(t *testing.T) {
+	err := Run(context.Background(), Config{}, func(Result) {})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("error == %#v, want invalidConfigError", err)
+	}
 }
@@ -0,0 +1,204 @@
+package load
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// Request is a template for requests sent during load generation.
+type Request struct {
+	// Name identifies the request in error messages. Optional.
+	Name string `yaml:"name"`
+	// Weight is the share of requests sent using this template, relative to
+	// the weights of all templates. Defaults to 1.
+	Weight int `yaml:"weight"`
+	// Method defaults to GET.
+	Method string `yaml:"method"`
+	// Path is resolved against the URL of the configuration, so it can be an
+	// absolute path like "/delay/3" or a full URL.
+	Path string `yaml:"path"`
+	// Headers are set on every request. A "Host" header overrides the host
+	// sent to the server, e.g. to reach an ingress by IP.
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+	// ExpectedStatus is the status code of a successful response. If zero,
+	// every response below 500 is successful.
+	ExpectedStatus int `yaml:"expected_status"`
+}
+
+// Profile describes a mix of requests, e.g. for exercising several endpoints
+// of the test app at once. It is usually read from a YAML file like
+//
+//	new_connections: false
+//	requests:
+//	- name: fast
+//	  weight: 3
+//	  path: /delay/0
+//	  expected_status: 200
+//	- name: slow
+//	  path: /delay/3
+type Profile struct {
+	// NewConnections makes every request use a new connection instead of
+	// keeping connections alive, to stress connection handling, e.g. of the
+	// ingress controller.
+	NewConnections bool      `yaml:"new_connections"`
+	Requests       []Request `yaml:"requests"`
+}
+
+// Apply returns a copy of config sending requests as described by the
+// profile.
+func (p Profile) Apply(config Config) Config {
+	config.Requests = p.Requests
+	config.NewConnections = p.NewConnections
+
+	return config
+}
+
+// LoadProfile reads and validates the profile at path.
+func LoadProfile(fileSystem afero.Fs, path string) (Profile, error) {
+	data, err := afero.ReadFile(fileSystem, path)
+	if err != nil {
+		return Profile{}, microerror.Mask(err)
+	}
+
+	profile, err := ParseProfile(data)
+	if err != nil {
+		return Profile{}, microerror.Mask(err)
+	}
+
+	return profile, nil
+}
+
+// ParseProfile parses and validates a profile in YAML.
+func ParseProfile(data []byte) (Profile, error) {
+	var profile Profile
+	err := yaml.UnmarshalStrict(data, &profile)
+	if err != nil {
+		return Profile{}, microerror.Maskf(invalidProfileError, "%s", err.Error())
+	}
+
+	if len(profile.Requests) == 0 {
+		return Profile{}, microerror.Maskf(invalidProfileError, "no requests defined")
+	}
+	problems := requestProblems(profile.Requests)
+	if len(problems) > 0 {
+		return Profile{}, microerror.Maskf(invalidProfileError, "%s", strings.Join(problems, ", "))
+	}
+
+	return profile, nil
+}
+
+// requestProblems returns a description of every problem found in the
+// requests.
+func requestProblems(requests []Request) []string {
+	var problems []string
+
+	var total int
+	for i, r := range requests {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+
+		if r.Weight < 0 {
+			problems = append(problems, fmt.Sprintf("request %s: weight must not be negative", name))
+		}
+		total += r.weight()
+		if strings.ContainsAny(r.Method, " \t\r\n") {
+			problems = append(problems, fmt.Sprintf("request %s: invalid method %q", name, r.Method))
+		}
+		_, err := url.Parse(r.Path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("request %s: invalid path: %s", name, err.Error()))
+		}
+		if r.ExpectedStatus != 0 && (r.ExpectedStatus < 100 || r.ExpectedStatus > 599) {
+			problems = append(problems, fmt.Sprintf("request %s: invalid expected status %d", name, r.ExpectedStatus))
+		}
+	}
+	if len(requests) > 0 && total == 0 {
+		problems = append(problems, "the weights of all requests are zero")
+	}
+
+	return problems
+}
+
+func (r Request) weight() int {
+	if r.Weight == 0 {
+		return 1
+	}
+	if r.Weight < 0 {
+		return 0
+	}
+
+	return r.Weight
+}
+
+// target is a request template with the URL resolved.
+type target struct {
+	request Request
+	url     string
+}
+
+// picker chooses targets randomly according to their weights.
+type picker struct {
+	targets []target
+	// cumulative holds the sum of the weights of all targets up to and
+	// including the one with the same index.
+	cumulative []int
+}
+
+// newPicker resolves the requests of the config against its URL. Without
+// requests, every request is a GET of the URL.
+func newPicker(config Config) (*picker, error) {
+	requests := config.Requests
+	if len(requests) == 0 {
+		requests = []Request{{}}
+	}
+
+	base, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.URL: %s", config, err.Error())
+	}
+
+	p := &picker{}
+	var total int
+	for _, r := range requests {
+		if r.Method == "" {
+			r.Method = http.MethodGet
+		}
+		ref, err := url.Parse(r.Path)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%s", err.Error())
+		}
+
+		total += r.weight()
+		p.targets = append(p.targets, target{request: r, url: base.ResolveReference(ref).String()})
+		p.cumulative = append(p.cumulative, total)
+	}
+
+	return p, nil
+}
+
+// pick returns a random target. rnd is passed in, as a rand.Rand is not safe
+// for concurrent use.
+func (p *picker) pick(rnd *rand.Rand) target {
+	if len(p.targets) == 1 {
+		return p.targets[0]
+	}
+
+	n := rnd.Intn(p.cumulative[len(p.cumulative)-1])
+	for i, c := range p.cumulative {
+		if n < c {
+			return p.targets[i]
+		}
+	}
+
+	return p.targets[len(p.targets)-1]
+}
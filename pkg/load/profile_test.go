@@ -0,0 +1,255 @@
+package load
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func Test_ParseProfile(t *testing.T) {
+	testCases := []struct {
+		name            string
+		data            string
+		expectedProfile Profile
+		errorMatcher    func(error) bool
+	}{
+		{
+			name: "case 0: full profile",
+			data: `new_connections: true
+requests:
+- name: fast
+  weight: 3
+  path: /delay/0
+  expected_status: 200
+- name: post
+  method: POST
+  path: /anything
+  headers:
+    Content-Type: application/json
+  body: '{"a": 1}'
+`,
+			expectedProfile: Profile{
+				NewConnections: true,
+				Requests: []Request{
+					{Name: "fast", Weight: 3, Path: "/delay/0", ExpectedStatus: 200},
+					{Name: "post", Method: "POST", Path: "/anything", Headers: map[string]string{"Content-Type": "application/json"}, Body: `{"a": 1}`},
+				},
+			},
+		},
+		{
+			name:         "case 1: no requests",
+			data:         "new_connections: true\n",
+			errorMatcher: IsInvalidProfile,
+		},
+		{
+			name:         "case 2: unknown field",
+			data:         "requests:\n- path: /\n  weigth: 2\n",
+			errorMatcher: IsInvalidProfile,
+		},
+		{
+			name:         "case 3: negative weight",
+			data:         "requests:\n- path: /\n  weight: -1\n",
+			errorMatcher: IsInvalidProfile,
+		},
+		{
+			name:         "case 4: invalid expected status",
+			data:         "requests:\n- path: /\n  expected_status: 2000\n",
+			errorMatcher: IsInvalidProfile,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			profile, err := ParseProfile([]byte(tc.data))
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("%s: error == nil, want non-nil", tc.name)
+			case !tc.errorMatcher(err):
+				t.Fatalf("%s: error == %#v, want matching", tc.name, err)
+			}
+
+			if !cmp.Equal(profile, tc.expectedProfile) {
+				t.Fatalf("%s: profile == %#v, want %#v\n%s", tc.name, profile, tc.expectedProfile, cmp.Diff(tc.expectedProfile, profile))
+			}
+		})
+	}
+}
+
+func Test_LoadProfile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "profile.yaml", []byte("requests:\n- path: /delay/1\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := LoadProfile(fs, "profile.yaml")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if len(profile.Requests) != 1 || profile.Requests[0].Path != "/delay/1" {
+		t.Fatalf("profile == %#v, want one request of /delay/1", profile)
+	}
+}
+
+func Test_picker(t *testing.T) {
+	p, err := newPicker(Config{
+		URL: "http://test.example.com/delay/1",
+		Requests: []Request{
+			{Name: "a", Weight: 3, Path: "/a"},
+			{Name: "b", Path: "b"},
+			{Name: "never", Weight: -1, Path: "/never"},
+			{Name: "other", Path: "https://other.example.com/"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedURLs := map[string]string{
+		"a":     "http://test.example.com/a",
+		"b":     "http://test.example.com/delay/b",
+		"other": "https://other.example.com/",
+	}
+
+	counts := map[string]int{}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		target := p.pick(rnd)
+		if target.url != expectedURLs[target.request.Name] {
+			t.Fatalf("url of %s == %q, want %q", target.request.Name, target.url, expectedURLs[target.request.Name])
+		}
+		if target.request.Method != http.MethodGet {
+			t.Fatalf("method == %q, want GET", target.request.Method)
+		}
+		counts[target.request.Name]++
+	}
+
+	if counts["never"] != 0 {
+		t.Fatalf("request with negative weight picked %d times", counts["never"])
+	}
+	// Weights 3:1:1 of 5000 requests.
+	if counts["a"] < 2800 || counts["a"] > 3200 || counts["b"] < 850 || counts["b"] > 1150 {
+		t.Fatalf("counts == %v, want about a=3000 b=1000 other=1000", counts)
+	}
+}
+
+func Test_Run_Profile(t *testing.T) {
+	testCases := []struct {
+		name           string
+		newConnections bool
+		maxConnections int
+		minConnections int
+	}{
+		{
+			name:           "case 0: connections are kept alive",
+			maxConnections: 2,
+			minConnections: 1,
+		},
+		{
+			name:           "case 1: every request uses a new connection",
+			newConnections: true,
+			maxConnections: 1000,
+			minConnections: 10,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var mutex sync.Mutex
+			var connections int
+			var bodies []string
+
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/post":
+					if r.Method != http.MethodPost || r.Header.Get("X-Test") != "yes" || r.Host != "test.example.com" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					body, _ := ioutil.ReadAll(r.Body)
+					mutex.Lock()
+					bodies = append(bodies, string(body))
+					mutex.Unlock()
+					w.WriteHeader(http.StatusCreated)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			server.Config.ConnState = func(c net.Conn, s http.ConnState) {
+				if s == http.StateNew {
+					mutex.Lock()
+					connections++
+					mutex.Unlock()
+				}
+			}
+			server.Start()
+			defer server.Close()
+
+			profile := Profile{
+				NewConnections: tc.newConnections,
+				Requests: []Request{
+					{
+						Method:         http.MethodPost,
+						Path:           "/post",
+						Headers:        map[string]string{"X-Test": "yes", "Host": "test.example.com"},
+						Body:           "hello",
+						ExpectedStatus: http.StatusCreated,
+					},
+					{
+						Path:           "/missing",
+						ExpectedStatus: http.StatusOK,
+					},
+				},
+			}
+
+			var requests, failed int
+			record := func(r Result) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				requests++
+				if r.Failed() {
+					failed++
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+
+			err := Run(ctx, profile.Apply(Config{URL: server.URL, Rate: 100}), record)
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if len(bodies) == 0 || failed == 0 || len(bodies)+failed != requests {
+				t.Fatalf("%s: %d requests, %d posted bodies, %d failed, want every GET of /missing to fail", tc.name, requests, len(bodies), failed)
+			}
+			for _, b := range bodies {
+				if b != "hello" {
+					t.Fatalf("%s: body == %q, want %q", tc.name, b, "hello")
+				}
+			}
+			if connections < tc.minConnections || connections > tc.maxConnections {
+				t.Fatalf("%s: %d connections, want %d to %d", tc.name, connections, tc.minConnections, tc.maxConnections)
+			}
+		})
+	}
+}
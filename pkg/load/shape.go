@@ -0,0 +1,118 @@
+package load
+
+import (
+	"math"
+	"time"
+)
+
+// Shape defines how the target rate changes during load generation, e.g. to
+// observe how ingress and autoscaling respond to changing load.
+type Shape interface {
+	// Rate returns the target number of requests per second at the given
+	// time since load generation started. Negative rates count as zero.
+	Rate(elapsed time.Duration) float64
+}
+
+// ShapeFunc adapts a function to the Shape interface.
+type ShapeFunc func(elapsed time.Duration) float64
+
+// Rate calls f.
+func (f ShapeFunc) Rate(elapsed time.Duration) float64 {
+	return f(elapsed)
+}
+
+// Constant keeps the rate the same all the time.
+type Constant float64
+
+// Rate returns c.
+func (c Constant) Rate(elapsed time.Duration) float64 {
+	return float64(c)
+}
+
+// Ramp changes the rate linearly from From to To within Duration and keeps
+// it at To afterwards.
+type Ramp struct {
+	From     float64
+	To       float64
+	Duration time.Duration
+}
+
+// Rate returns the rate on the ramp.
+func (r Ramp) Rate(elapsed time.Duration) float64 {
+	if elapsed >= r.Duration {
+		return r.To
+	}
+
+	return r.From + (r.To-r.From)*float64(elapsed)/float64(r.Duration)
+}
+
+// Step is a plateau of Steps.
+type Step struct {
+	Rate     float64
+	Duration time.Duration
+}
+
+// Steps holds the rate of each step for its duration, one after the other.
+// The rate of the last step is kept afterwards.
+type Steps []Step
+
+// Rate returns the rate of the current step.
+func (s Steps) Rate(elapsed time.Duration) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+
+	for _, step := range s {
+		if elapsed < step.Duration {
+			return step.Rate
+		}
+		elapsed -= step.Duration
+	}
+
+	return s[len(s)-1].Rate
+}
+
+// Spike keeps the rate at Base, except for spikes of Duration at Peak. The
+// first spike starts after Delay. If Every is set, spikes repeat in this
+// period of time.
+type Spike struct {
+	Base     float64
+	Peak     float64
+	Delay    time.Duration
+	Duration time.Duration
+	Every    time.Duration
+}
+
+// Rate returns Peak during a spike and Base otherwise.
+func (s Spike) Rate(elapsed time.Duration) float64 {
+	if elapsed < s.Delay {
+		return s.Base
+	}
+
+	elapsed -= s.Delay
+	if s.Every > 0 {
+		elapsed %= s.Every
+	}
+	if elapsed < s.Duration {
+		return s.Peak
+	}
+
+	return s.Base
+}
+
+// Sine oscillates the rate around Mean by Amplitude, starting at Mean and
+// increasing first, completing one oscillation every Period.
+type Sine struct {
+	Mean      float64
+	Amplitude float64
+	Period    time.Duration
+}
+
+// Rate returns the rate on the sine curve.
+func (s Sine) Rate(elapsed time.Duration) float64 {
+	if s.Period <= 0 {
+		return s.Mean
+	}
+
+	return s.Mean + s.Amplitude*math.Sin(2*math.Pi*float64(elapsed)/float64(s.Period))
+}
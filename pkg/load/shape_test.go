@@ -0,0 +1,219 @@
+package load
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Shape_Rate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		shape    Shape
+		elapsed  time.Duration
+		expected float64
+	}{
+		{
+			name:     "case 0: constant",
+			shape:    Constant(20),
+			elapsed:  time.Hour,
+			expected: 20,
+		},
+		{
+			name:     "case 1: ramp half way",
+			shape:    Ramp{From: 10, To: 110, Duration: 10 * time.Second},
+			elapsed:  5 * time.Second,
+			expected: 60,
+		},
+		{
+			name:     "case 2: ramp down",
+			shape:    Ramp{From: 100, To: 0, Duration: 10 * time.Second},
+			elapsed:  9 * time.Second,
+			expected: 10,
+		},
+		{
+			name:     "case 3: ramp keeps final rate",
+			shape:    Ramp{From: 10, To: 110, Duration: 10 * time.Second},
+			elapsed:  time.Minute,
+			expected: 110,
+		},
+		{
+			name:     "case 4: second step",
+			shape:    Steps{{Rate: 10, Duration: time.Minute}, {Rate: 20, Duration: time.Minute}, {Rate: 30, Duration: time.Minute}},
+			elapsed:  90 * time.Second,
+			expected: 20,
+		},
+		{
+			name:     "case 5: steps keep last rate",
+			shape:    Steps{{Rate: 10, Duration: time.Minute}, {Rate: 30, Duration: time.Minute}},
+			elapsed:  time.Hour,
+			expected: 30,
+		},
+		{
+			name:     "case 6: no steps",
+			shape:    Steps{},
+			elapsed:  time.Second,
+			expected: 0,
+		},
+		{
+			name:     "case 7: before spike",
+			shape:    Spike{Base: 10, Peak: 100, Delay: time.Minute, Duration: 10 * time.Second},
+			elapsed:  59 * time.Second,
+			expected: 10,
+		},
+		{
+			name:     "case 8: during spike",
+			shape:    Spike{Base: 10, Peak: 100, Delay: time.Minute, Duration: 10 * time.Second},
+			elapsed:  65 * time.Second,
+			expected: 100,
+		},
+		{
+			name:     "case 9: after single spike",
+			shape:    Spike{Base: 10, Peak: 100, Delay: time.Minute, Duration: 10 * time.Second},
+			elapsed:  5 * time.Minute,
+			expected: 10,
+		},
+		{
+			name:     "case 10: repeated spike",
+			shape:    Spike{Base: 10, Peak: 100, Delay: time.Minute, Duration: 10 * time.Second, Every: time.Minute},
+			elapsed:  5*time.Minute + 5*time.Second,
+			expected: 100,
+		},
+		{
+			name:     "case 11: sine at quarter period",
+			shape:    Sine{Mean: 50, Amplitude: 20, Period: time.Minute},
+			elapsed:  15 * time.Second,
+			expected: 70,
+		},
+		{
+			name:     "case 12: sine at three quarters period",
+			shape:    Sine{Mean: 50, Amplitude: 20, Period: time.Minute},
+			elapsed:  45 * time.Second,
+			expected: 30,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			rate := tc.shape.Rate(tc.elapsed)
+			if math.Abs(rate-tc.expected) > 1e-9 {
+				t.Fatalf("%s: rate == %f, want %f", tc.name, rate, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_Run_Shape(t *testing.T) {
+	testCases := []struct {
+		name        string
+		shape       Shape
+		minRequests int
+		maxRequests int
+	}{
+		{
+			name:        "case 0: ramp from zero sends half the requests of the final rate",
+			shape:       Ramp{From: 0, To: 100, Duration: time.Second},
+			minRequests: 42,
+			maxRequests: 58,
+		},
+		{
+			name:        "case 1: low rates accumulate",
+			shape:       Constant(4),
+			minRequests: 4,
+			maxRequests: 5,
+		},
+		{
+			name:        "case 2: zero rate sends nothing",
+			shape:       Constant(0),
+			minRequests: 0,
+			maxRequests: 0,
+		},
+		{
+			name:        "case 3: spike",
+			shape:       Spike{Base: 10, Peak: 100, Delay: 500 * time.Millisecond, Duration: 200 * time.Millisecond},
+			minRequests: 22,
+			maxRequests: 32,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`OK`))
+			}))
+			defer server.Close()
+
+			var mutex sync.Mutex
+			var requests int
+			record := func(r Result) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if !r.Dropped {
+					requests++
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			err := Run(ctx, Config{URL: server.URL, Workers: 4, Shape: tc.shape}, record)
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+
+			if requests < tc.minRequests || requests > tc.maxRequests {
+				t.Fatalf("%s: %d requests, want %d to %d", tc.name, requests, tc.minRequests, tc.maxRequests)
+			}
+		})
+	}
+}
+
+func Test_Generator_TargetRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`OK`))
+	}))
+	defer server.Close()
+
+	g, err := New(Config{
+		URL:      server.URL,
+		Shape:    Steps{{Rate: 10, Duration: 300 * time.Millisecond}, {Rate: 50, Duration: time.Hour}},
+		Interval: 200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	err = g.Start(context.Background())
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	err = g.Stop()
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	intervals := g.Results().Intervals
+	if len(intervals) < 2 {
+		t.Fatalf("%d intervals, want at least 2", len(intervals))
+	}
+	if intervals[0].TargetRate != 10 {
+		t.Fatalf("target rate of first interval == %f, want 10", intervals[0].TargetRate)
+	}
+	if intervals[len(intervals)-1].TargetRate != 50 {
+		t.Fatalf("target rate of last interval == %f, want 50", intervals[len(intervals)-1].TargetRate)
+	}
+}
+
+func Test_Config_RateAndShape(t *testing.T) {
+	_, err := New(Config{URL: "http://localhost", Rate: 10, Shape: Constant(10)})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("error == %#v, want invalidConfigError", err)
+	}
+}
@@ -0,0 +1,145 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/afero"
+)
+
+// Period tells sinks which period of time statistics cover.
+type Period string
+
+const (
+	// PeriodInterval marks the statistics of one interval.
+	PeriodInterval Period = "interval"
+	// PeriodOverall marks the statistics of the whole run.
+	PeriodOverall Period = "overall"
+)
+
+// Sink receives statistics while load is generated.
+type Sink interface {
+	// Write is called with the statistics of every interval and finally
+	// once with the statistics of the whole run.
+	Write(period Period, stats Stats) error
+}
+
+// SinkFunc adapts a function to the Sink interface, e.g. to assert on
+// statistics while load is generated.
+type SinkFunc func(period Period, stats Stats) error
+
+// Write calls f.
+func (f SinkFunc) Write(period Period, stats Stats) error {
+	return f(period, stats)
+}
+
+// NewWriterSink returns a sink writing one human readable line per call to w.
+func NewWriterSink(w io.Writer) Sink {
+	return SinkFunc(func(period Period, stats Stats) error {
+		_, err := fmt.Fprintf(w, "%s %s: %s\n", stats.Start.Format("2006-01-02T15:04:05"), period, stats)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	})
+}
+
+// NewStdoutSink returns a sink writing human readable lines to stdout.
+func NewStdoutSink() Sink {
+	return NewWriterSink(os.Stdout)
+}
+
+// NewFileSink returns a sink appending human readable lines to the file at
+// path, which is created if necessary.
+func NewFileSink(fileSystem afero.Fs, path string) Sink {
+	return SinkFunc(func(period Period, stats Stats) error {
+		f, err := fileSystem.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		defer f.Close()
+
+		err = NewWriterSink(f).Write(period, stats)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	})
+}
+
+// jsonRecord is the JSON representation of statistics written by the JSON
+// sink.
+type jsonRecord struct {
+	Period      Period                  `json:"period"`
+	Start       string                  `json:"start"`
+	DurationMS  int64                   `json:"duration_ms"`
+	Requests    int64                   `json:"requests"`
+	Successes   int64                   `json:"successes"`
+	Failures    int64                   `json:"failures"`
+	Dropped     int64                   `json:"dropped"`
+	ErrorRate   float64                 `json:"error_rate"`
+	RequestRate float64                 `json:"request_rate"`
+	StatusCodes map[int]int64           `json:"status_codes"`
+	Errors      map[ErrorCategory]int64 `json:"errors"`
+	LatencyMS   jsonLatency             `json:"latency_ms"`
+	// LongestOutageMS is the longest outage in milliseconds.
+	LongestOutageMS int64 `json:"longest_outage_ms"`
+	// TargetRate is the target number of requests per second at the end of
+	// the period.
+	TargetRate float64 `json:"target_rate"`
+}
+
+type jsonLatency struct {
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+}
+
+// NewJSONSink returns a sink writing one JSON object per line to w.
+func NewJSONSink(w io.Writer) Sink {
+	encoder := json.NewEncoder(w)
+
+	return SinkFunc(func(period Period, stats Stats) error {
+		record := jsonRecord{
+			Period:      period,
+			Start:       stats.Start.Format("2006-01-02T15:04:05.000Z07:00"),
+			DurationMS:  stats.Duration.Milliseconds(),
+			Requests:    stats.Requests,
+			Successes:   stats.Successes,
+			Failures:    stats.Failures,
+			Dropped:     stats.Dropped,
+			ErrorRate:   stats.ErrorRate(),
+			RequestRate: stats.RequestRate(),
+			StatusCodes: stats.StatusCodes,
+			Errors:      stats.Errors,
+			LatencyMS: jsonLatency{
+				P50:  milliseconds(stats.Latency.P50),
+				P90:  milliseconds(stats.Latency.P90),
+				P99:  milliseconds(stats.Latency.P99),
+				Max:  milliseconds(stats.Latency.Max),
+				Mean: milliseconds(stats.Latency.Mean),
+			},
+			LongestOutageMS: stats.LongestOutage.Milliseconds(),
+			TargetRate:      stats.TargetRate,
+		}
+
+		err := encoder.Encode(record)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	})
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
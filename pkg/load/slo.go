@@ -0,0 +1,58 @@
+package load
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+// SLO defines availability expectations for an endpoint under load, e.g.
+// "error rate below 0.1% and no outage longer than 5s".
+type SLO struct {
+	// MaxErrorRate is the highest acceptable fraction of failed requests,
+	// e.g. 0.001 for 0.1%. Zero disables the check.
+	MaxErrorRate float64
+	// MaxOutage is the longest acceptable period of time from a failed
+	// request to the next successful one. Zero disables the check.
+	MaxOutage time.Duration
+}
+
+func (s SLO) String() string {
+	var parts []string
+	if s.MaxErrorRate > 0 {
+		parts = append(parts, fmt.Sprintf("error rate <= %g%%", s.MaxErrorRate*100))
+	}
+	if s.MaxOutage > 0 {
+		parts = append(parts, fmt.Sprintf("no outage longer than %s", s.MaxOutage))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+
+	return strings.Join(parts, " and ")
+}
+
+// Check returns a sloViolatedError describing all violations if the
+// statistics don't meet the SLO. Statistics without any sent request violate
+// every SLO, as availability can't be judged.
+func (s SLO) Check(stats Stats) error {
+	var violations []string
+
+	if stats.Requests == 0 {
+		violations = append(violations, "no requests have been sent")
+	}
+	if s.MaxErrorRate > 0 && stats.ErrorRate() > s.MaxErrorRate {
+		violations = append(violations, fmt.Sprintf("error rate %g%% exceeds %g%%", stats.ErrorRate()*100, s.MaxErrorRate*100))
+	}
+	if s.MaxOutage > 0 && stats.LongestOutage > s.MaxOutage {
+		violations = append(violations, fmt.Sprintf("outage of %s exceeds %s", stats.LongestOutage, s.MaxOutage))
+	}
+
+	if len(violations) > 0 {
+		return microerror.Maskf(sloViolatedError, "%s", strings.Join(violations, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package load
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_SLO_Check(t *testing.T) {
+	testCases := []struct {
+		name         string
+		slo          SLO
+		stats        Stats
+		errorMatcher func(error) bool
+	}{
+		{
+			name:  "case 0: SLO met",
+			slo:   SLO{MaxErrorRate: 0.01, MaxOutage: 5 * time.Second},
+			stats: Stats{Requests: 1000, Successes: 995, Failures: 5, LongestOutage: 2 * time.Second},
+		},
+		{
+			name:         "case 1: error rate too high",
+			slo:          SLO{MaxErrorRate: 0.001},
+			stats:        Stats{Requests: 1000, Successes: 998, Failures: 2},
+			errorMatcher: IsSLOViolated,
+		},
+		{
+			name:         "case 2: outage too long",
+			slo:          SLO{MaxOutage: 5 * time.Second},
+			stats:        Stats{Requests: 1000, Successes: 999, Failures: 1, LongestOutage: 6 * time.Second},
+			errorMatcher: IsSLOViolated,
+		},
+		{
+			name:  "case 3: zero values disable checks",
+			slo:   SLO{},
+			stats: Stats{Requests: 10, Failures: 10, LongestOutage: time.Minute},
+		},
+		{
+			name:         "case 4: no requests",
+			slo:          SLO{MaxErrorRate: 0.001},
+			stats:        Stats{},
+			errorMatcher: IsSLOViolated,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := tc.slo.Check(tc.stats)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("%s: error == nil, want non-nil", tc.name)
+			case !tc.errorMatcher(err):
+				t.Fatalf("%s: error == %#v, want matching", tc.name, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package load
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+// ErrorCategory classifies failed requests.
+type ErrorCategory string
+
+const (
+	ErrorCategoryTimeout           ErrorCategory = "timeout"
+	ErrorCategoryDNS               ErrorCategory = "dns"
+	ErrorCategoryConnectionRefused ErrorCategory = "connection_refused"
+	ErrorCategoryServerError       ErrorCategory = "5xx"
+	ErrorCategoryUnexpectedStatus  ErrorCategory = "unexpected_status"
+	ErrorCategoryOther             ErrorCategory = "other"
+)
+
+// LatencySummary holds percentiles of request latencies.
+type LatencySummary struct {
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+	Mean time.Duration
+}
+
+// Stats summarizes the results of the requests started in a period of time.
+type Stats struct {
+	Start    time.Time
+	Duration time.Duration
+
+	// Requests is the number of requests sent, i.e. Successes plus Failures.
+	Requests  int64
+	Successes int64
+	Failures  int64
+	// Dropped is the number of requests not sent because all workers were
+	// busy.
+	Dropped int64
+
+	// StatusCodes counts responses by status code.
+	StatusCodes map[int]int64
+	// Errors counts failed requests by category.
+	Errors map[ErrorCategory]int64
+	// Latency summarizes the latencies of all responses, including failed
+	// ones with a status code.
+	Latency LatencySummary
+	// LongestOutage is the longest period of time from a failed request to
+	// the next successful one. An outage lasting until the end of the period
+	// is included.
+	LongestOutage time.Duration
+	// TargetRate is the target number of requests per second at the end of
+	// the period, or zero in closed loop mode. It changes over time if the
+	// load has a Shape.
+	TargetRate float64
+}
+
+// ErrorRate returns the fraction of sent requests which failed.
+func (s Stats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+
+	return float64(s.Failures) / float64(s.Requests)
+}
+
+// RequestRate returns the number of requests sent per second.
+func (s Stats) RequestRate() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+
+	return float64(s.Requests) / s.Duration.Seconds()
+}
+
+func (s Stats) String() string {
+	var codes []string
+	for _, code := range sortedKeys(s.StatusCodes) {
+		codes = append(codes, fmt.Sprintf("%d=%d", code, s.StatusCodes[code]))
+	}
+
+	var categories []string
+	for category, count := range s.Errors {
+		categories = append(categories, fmt.Sprintf("%s=%d", category, count))
+	}
+	sort.Strings(categories)
+
+	var target string
+	if s.TargetRate > 0 {
+		target = fmt.Sprintf(", target %.1f/s", s.TargetRate)
+	}
+
+	return fmt.Sprintf("requests %d (%.1f/s%s), successes %d, failures %d, dropped %d, error rate %.5f, longest outage %s, latency p50 %s p90 %s p99 %s max %s, status codes [%s], errors [%s]",
+		s.Requests, s.RequestRate(), target, s.Successes, s.Failures, s.Dropped, s.ErrorRate(), s.LongestOutage,
+		s.Latency.P50, s.Latency.P90, s.Latency.P99, s.Latency.Max,
+		strings.Join(codes, " "), strings.Join(categories, " "))
+}
+
+// Categorize returns the category of a failed request, or an empty string if
+// the request didn't fail.
+func Categorize(r Result) ErrorCategory {
+	if !r.Failed() {
+		return ""
+	}
+	if r.Err == nil {
+		if r.StatusCode >= 500 {
+			return ErrorCategoryServerError
+		}
+		return ErrorCategoryUnexpectedStatus
+	}
+
+	err := microerror.Cause(r.Err)
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorCategoryDNS
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorCategoryConnectionRefused
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorCategoryTimeout
+	}
+
+	return ErrorCategoryOther
+}
+
+// Collector aggregates results into statistics per interval and overall. It
+// is safe for concurrent use.
+type Collector struct {
+	mutex     sync.Mutex
+	overall   *window
+	current   *window
+	intervals []Stats
+	windows   map[*Window]struct{}
+
+	// targetRate returns the target rate at the given time. Optional.
+	targetRate func(at time.Time) float64
+}
+
+// NewCollector returns a collector whose first interval starts now.
+func NewCollector() *Collector {
+	now := time.Now()
+
+	return &Collector{
+		overall: newWindow(now),
+		current: newWindow(now),
+		windows: map[*Window]struct{}{},
+	}
+}
+
+// Record adds a result to the current interval and all open windows.
+func (c *Collector) Record(r Result) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.current.record(r)
+	c.overall.record(r)
+	for w := range c.windows {
+		w.window.record(r)
+	}
+}
+
+// Flush ends the current interval and returns its statistics. A new interval
+// starts right away.
+func (c *Collector) Flush() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	s := c.stats(c.current, now)
+	c.intervals = append(c.intervals, s)
+	c.current = newWindow(now)
+
+	return s
+}
+
+// Intervals returns the statistics of all flushed intervals.
+func (c *Collector) Intervals() []Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	intervals := make([]Stats, len(c.intervals))
+	copy(intervals, c.intervals)
+
+	return intervals
+}
+
+// StartWindow starts collecting the statistics of a period of time of
+// interest, e.g. while a step of a test runs, independent of intervals.
+func (c *Collector) StartWindow() *Window {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	w := &Window{
+		collector: c,
+		window:    newWindow(time.Now()),
+	}
+	c.windows[w] = struct{}{}
+
+	return w
+}
+
+// Overall returns the statistics of all results recorded so far.
+func (c *Collector) Overall() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.stats(c.overall, time.Now())
+}
+
+// stats returns the statistics of the window including the target rate. The
+// mutex must be held.
+func (c *Collector) stats(w *window, end time.Time) Stats {
+	s := w.stats(end)
+	if c.targetRate != nil {
+		s.TargetRate = c.targetRate(end)
+	}
+
+	return s
+}
+
+// Window collects statistics from its start until End is called.
+type Window struct {
+	collector *Collector
+	window    *window
+	stats     *Stats
+}
+
+// End stops collecting and returns the statistics of the window. Further
+// calls return the same statistics.
+func (w *Window) End() Stats {
+	w.collector.mutex.Lock()
+	defer w.collector.mutex.Unlock()
+
+	if w.stats == nil {
+		s := w.collector.stats(w.window, time.Now())
+		w.stats = &s
+		delete(w.collector.windows, w)
+	}
+
+	return *w.stats
+}
+
+// window accumulates the results of one period of time.
+type window struct {
+	start       time.Time
+	successes   int64
+	failures    int64
+	dropped     int64
+	statusCodes map[int]int64
+	errors      map[ErrorCategory]int64
+	latency     Histogram
+
+	// outageStart is the start of the first failed request since the last
+	// successful one.
+	outageStart   time.Time
+	longestOutage time.Duration
+}
+
+func newWindow(start time.Time) *window {
+	return &window{
+		start:       start,
+		statusCodes: map[int]int64{},
+		errors:      map[ErrorCategory]int64{},
+	}
+}
+
+func (w *window) record(r Result) {
+	if r.Dropped {
+		w.dropped++
+		return
+	}
+
+	if r.Failed() {
+		w.failures++
+		w.errors[Categorize(r)]++
+		if w.outageStart.IsZero() || r.Start.Before(w.outageStart) {
+			w.outageStart = r.Start
+		}
+	} else {
+		w.successes++
+		// Results arrive out of order. Only a request started after the
+		// outage started ends it.
+		if !w.outageStart.IsZero() && r.Start.After(w.outageStart) {
+			w.endOutage(r.Start)
+		}
+	}
+
+	if r.StatusCode != 0 {
+		w.statusCodes[r.StatusCode]++
+		w.latency.Record(r.Latency)
+	}
+}
+
+func (w *window) endOutage(end time.Time) {
+	if d := end.Sub(w.outageStart); d > w.longestOutage {
+		w.longestOutage = d
+	}
+	w.outageStart = time.Time{}
+}
+
+func (w *window) stats(end time.Time) Stats {
+	longestOutage := w.longestOutage
+	if !w.outageStart.IsZero() {
+		if d := end.Sub(w.outageStart); d > longestOutage {
+			longestOutage = d
+		}
+	}
+
+	s := Stats{
+		Start:       w.start,
+		Duration:    end.Sub(w.start),
+		Requests:    w.successes + w.failures,
+		Successes:   w.successes,
+		Failures:    w.failures,
+		Dropped:     w.dropped,
+		StatusCodes: map[int]int64{},
+		Errors:      map[ErrorCategory]int64{},
+		Latency: LatencySummary{
+			P50:  w.latency.Quantile(0.5),
+			P90:  w.latency.Quantile(0.9),
+			P99:  w.latency.Quantile(0.99),
+			Max:  w.latency.Max(),
+			Mean: w.latency.Mean(),
+		},
+		LongestOutage: longestOutage,
+	}
+	for code, count := range w.statusCodes {
+		s.StatusCodes[code] = count
+	}
+	for category, count := range w.errors {
+		s.Errors[category] = count
+	}
+
+	return s
+}
+
+func sortedKeys(m map[int]int64) []int {
+	var keys []int
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	return keys
+}
@@ -0,0 +1,172 @@
+package load
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+func Test_Categorize(t *testing.T) {
+	// A port nobody listens on.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedURL := "http://" + listener.Addr().String()
+	listener.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ok.Close()
+
+	testCases := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expected       ErrorCategory
+	}{
+		{
+			name:     "case 0: client error is no failure",
+			url:      ok.URL,
+			expected: "",
+		},
+		{
+			name:     "case 1: server error",
+			url:      failing.URL,
+			expected: ErrorCategoryServerError,
+		},
+		{
+			name:     "case 2: timeout",
+			url:      slow.URL,
+			expected: ErrorCategoryTimeout,
+		},
+		{
+			name:     "case 3: connection refused",
+			url:      closedURL,
+			expected: ErrorCategoryConnectionRefused,
+		},
+		{
+			name:     "case 4: unknown host",
+			url:      "http://does-not-exist.invalid",
+			expected: ErrorCategoryDNS,
+		},
+		{
+			name:           "case 5: unexpected status",
+			url:            ok.URL,
+			expectedStatus: http.StatusOK,
+			expected:       ErrorCategoryUnexpectedStatus,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			config := Config{
+				URL:        tc.url,
+				Timeout:    100 * time.Millisecond,
+				HTTPClient: http.DefaultClient,
+			}
+
+			category := Categorize(send(context.Background(), config, target{request: Request{Method: http.MethodGet, ExpectedStatus: tc.expectedStatus}, url: tc.url}))
+			if category != tc.expected {
+				t.Fatalf("%s: category == %q, want %q", tc.name, category, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_Collector(t *testing.T) {
+	c := NewCollector()
+
+	c.Record(Result{StatusCode: http.StatusOK, Latency: 10 * time.Millisecond})
+	c.Record(Result{StatusCode: http.StatusServiceUnavailable, Latency: 20 * time.Millisecond})
+	c.Record(Result{Dropped: true})
+
+	first := c.Flush()
+	if first.Requests != 2 || first.Successes != 1 || first.Failures != 1 || first.Dropped != 1 {
+		t.Fatalf("first interval == %s, want 2 requests, 1 success, 1 failure, 1 dropped", first)
+	}
+	if first.StatusCodes[http.StatusServiceUnavailable] != 1 || first.Errors[ErrorCategoryServerError] != 1 {
+		t.Fatalf("first interval == %s, want one 503 server error", first)
+	}
+	if first.ErrorRate() != 0.5 {
+		t.Fatalf("error rate == %f, want 0.5", first.ErrorRate())
+	}
+	if first.Latency.Max != 20*time.Millisecond {
+		t.Fatalf("max latency == %s, want 20ms", first.Latency.Max)
+	}
+
+	c.Record(Result{Err: microerror.Mask(context.DeadlineExceeded)})
+
+	second := c.Flush()
+	if second.Requests != 1 || second.Errors[ErrorCategoryTimeout] != 1 {
+		t.Fatalf("second interval == %s, want 1 timeout", second)
+	}
+
+	overall := c.Overall()
+	if overall.Requests != 3 || overall.Failures != 2 {
+		t.Fatalf("overall == %s, want 3 requests, 2 failures", overall)
+	}
+	if len(c.Intervals()) != 2 {
+		t.Fatalf("%d intervals, want 2", len(c.Intervals()))
+	}
+}
+
+func Test_Collector_Window(t *testing.T) {
+	start := time.Now()
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	c := NewCollector()
+	c.Record(Result{Start: at(0), StatusCode: http.StatusBadGateway})
+
+	w := c.StartWindow()
+	c.Record(Result{Start: at(1), StatusCode: http.StatusOK})
+	c.Record(Result{Start: at(2), StatusCode: http.StatusBadGateway})
+	// Results arrive out of order. A success started before the outage
+	// doesn't end it.
+	c.Record(Result{Start: at(1), StatusCode: http.StatusOK})
+	c.Record(Result{Start: at(3), StatusCode: http.StatusServiceUnavailable})
+	c.Record(Result{Start: at(5), StatusCode: http.StatusOK})
+
+	stats := w.End()
+	if stats.Requests != 5 || stats.Failures != 2 {
+		t.Fatalf("window == %s, want 5 requests, 2 failures", stats)
+	}
+	if stats.LongestOutage != 3*time.Second {
+		t.Fatalf("longest outage == %s, want 3s", stats.LongestOutage)
+	}
+
+	c.Record(Result{Start: at(6), StatusCode: http.StatusOK})
+	if again := w.End(); again.Requests != stats.Requests {
+		t.Fatalf("ended window == %s, want it unchanged", again)
+	}
+
+	overall := c.Overall()
+	if overall.Requests != 7 {
+		t.Fatalf("overall == %s, want 7 requests", overall)
+	}
+	if overall.LongestOutage != 3*time.Second {
+		t.Fatalf("overall longest outage == %s, want 3s", overall.LongestOutage)
+	}
+}
@@ -0,0 +1,173 @@
+// Package metrics exposes Prometheus metrics about test runs, calls to the
+// Giant Swarm API and generated load, so that long soak runs can be scraped.
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/load"
+)
+
+const (
+	namespace = "api_acceptance_test"
+
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+	outcomeDropped = "dropped"
+
+	// statusError is the status label value of requests without a response.
+	statusError = "error"
+)
+
+var (
+	stepDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "step_duration_seconds",
+			Help:      "Duration of test steps by step and outcome.",
+			// From one second to about four hours, as steps waiting for
+			// clusters or nodes take long.
+			Buckets: prometheus.ExponentialBuckets(1, 2, 15),
+		},
+		[]string{"step", "outcome"},
+	)
+
+	apiRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_request_duration_seconds",
+			Help:      "Latency of Giant Swarm API requests by operation and response status.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"operation", "status"},
+	)
+
+	loadRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "load_requests_total",
+			Help:      "Requests due during load generation by outcome, either success, failure or dropped.",
+		},
+		[]string{"outcome"},
+	)
+
+	loadErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "load_errors_total",
+			Help:      "Failed requests during load generation by error category.",
+		},
+		[]string{"category"},
+	)
+
+	loadRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "load_request_duration_seconds",
+			Help:      "Latency of responses during load generation by status.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"status"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		stepDuration,
+		apiRequestDuration,
+		loadRequests,
+		loadErrors,
+		loadRequestDuration,
+	)
+}
+
+// Serve serves the metrics at /metrics on the given address, e.g. ":9090", in
+// the background. The returned server can be closed to stop serving.
+func Serve(address string) (*http.Server, error) {
+	// Listen right away, so that e.g. an address in use is reported to the
+	// caller.
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	server := &http.Server{
+		Handler: mux,
+	}
+	go func() {
+		// Serve only returns on errors or when the server is closed, which
+		// is of no interest.
+		_ = server.Serve(listener)
+	}()
+
+	return server, nil
+}
+
+// Step measures the duration of a test step.
+type Step struct {
+	name  string
+	start time.Time
+}
+
+// StartStep starts measuring the duration of the step with the given name,
+// e.g. "4a".
+func StartStep(name string) *Step {
+	return &Step{
+		name:  name,
+		start: time.Now(),
+	}
+}
+
+// End records the duration of the step, which failed if err is set.
+func (s *Step) End(err error) {
+	outcome := outcomeSuccess
+	if err != nil {
+		outcome = outcomeFailure
+	}
+
+	stepDuration.WithLabelValues(s.name, outcome).Observe(time.Since(s.start).Seconds())
+}
+
+// ObserveAPIRequest records the latency of a Giant Swarm API request. A status
+// code of zero means no response has been received.
+func ObserveAPIRequest(operation string, statusCode int, latency time.Duration) {
+	apiRequestDuration.WithLabelValues(operation, status(statusCode)).Observe(latency.Seconds())
+}
+
+// ObserveLoadResult records the result of a request sent during load
+// generation. It can be used as load.Config.Observe.
+func ObserveLoadResult(r load.Result) {
+	switch {
+	case r.Dropped:
+		loadRequests.WithLabelValues(outcomeDropped).Inc()
+		return
+	case r.Failed():
+		loadRequests.WithLabelValues(outcomeFailure).Inc()
+		loadErrors.WithLabelValues(string(load.Categorize(r))).Inc()
+	default:
+		loadRequests.WithLabelValues(outcomeSuccess).Inc()
+	}
+
+	// Like load statistics, latencies only cover responses.
+	if r.StatusCode != 0 {
+		loadRequestDuration.WithLabelValues(status(r.StatusCode)).Observe(r.Latency.Seconds())
+	}
+}
+
+func status(code int) string {
+	if code == 0 {
+		return statusError
+	}
+
+	return strconv.Itoa(code)
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/load"
+)
+
+func Test_ObserveLoadResult(t *testing.T) {
+	testCases := []struct {
+		name     string
+		result   load.Result
+		outcome  string
+		category load.ErrorCategory
+	}{
+		{
+			name:    "case 0: success",
+			result:  load.Result{StatusCode: http.StatusOK, Latency: time.Millisecond},
+			outcome: outcomeSuccess,
+		},
+		{
+			name:     "case 1: server error",
+			result:   load.Result{StatusCode: http.StatusBadGateway, Latency: time.Millisecond},
+			outcome:  outcomeFailure,
+			category: load.ErrorCategoryServerError,
+		},
+		{
+			name:     "case 2: no response",
+			result:   load.Result{Err: errors.New("connection reset")},
+			outcome:  outcomeFailure,
+			category: load.ErrorCategoryOther,
+		},
+		{
+			name:    "case 3: dropped",
+			result:  load.Result{Dropped: true},
+			outcome: outcomeDropped,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			requests := testutil.ToFloat64(loadRequests.WithLabelValues(tc.outcome))
+			var errs float64
+			if tc.category != "" {
+				errs = testutil.ToFloat64(loadErrors.WithLabelValues(string(tc.category)))
+			}
+
+			ObserveLoadResult(tc.result)
+
+			if d := testutil.ToFloat64(loadRequests.WithLabelValues(tc.outcome)) - requests; d != 1 {
+				t.Fatalf("%s: %s requests increased by %f, want 1", tc.name, tc.outcome, d)
+			}
+			if tc.category != "" {
+				if d := testutil.ToFloat64(loadErrors.WithLabelValues(string(tc.category))) - errs; d != 1 {
+					t.Fatalf("%s: %s errors increased by %f, want 1", tc.name, tc.category, d)
+				}
+			}
+		})
+	}
+
+	// Only responses have a latency, one with status 200 and one with 502.
+	if n := testutil.CollectAndCount(loadRequestDuration); n != 2 {
+		t.Fatalf("%d latency series, want 2", n)
+	}
+}
+
+func Test_Serve(t *testing.T) {
+	// Find a free port.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	server, err := Serve(address)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	defer server.Close()
+
+	_, err = Serve(address)
+	if err == nil {
+		t.Fatalf("error == nil, want non-nil for an address in use")
+	}
+
+	// The registry is global, so only increases are checked, allowing the
+	// test to run repeatedly.
+	series := []string{
+		`api_acceptance_test_step_duration_seconds_count{outcome="success",step="1"}`,
+		`api_acceptance_test_step_duration_seconds_count{outcome="failure",step="2"}`,
+		`api_acceptance_test_api_request_duration_seconds_count{operation="getClusters",status="200"}`,
+		`api_acceptance_test_api_request_duration_seconds_count{operation="getClusters",status="error"}`,
+	}
+	before := scrape(t, address)
+
+	StartStep("1").End(nil)
+	StartStep("2").End(errors.New("failed"))
+	ObserveAPIRequest("getClusters", http.StatusOK, 100*time.Millisecond)
+	ObserveAPIRequest("getClusters", 0, time.Second)
+
+	after := scrape(t, address)
+
+	for _, s := range series {
+		if d := after[s] - before[s]; d != 1 {
+			t.Fatalf("%s increased by %f, want 1", s, d)
+		}
+	}
+}
+
+// scrape gets the metrics served at address and returns the value of each
+// series.
+func scrape(t *testing.T, address string) map[string]float64 {
+	resp, err := http.Get("http://" + address + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string]float64{}
+	for _, line := range strings.Split(string(body), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndex(line, " ")
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("metrics line %q can't be parsed: %s", line, err)
+		}
+		values[line[:i]] = v
+	}
+
+	return values
+}
@@ -0,0 +1,21 @@
+package testapp
+
+import "github.com/giantswarm/microerror"
+
+var invalidTemplateError = &microerror.Error{
+	Kind: "invalidTemplateError",
+}
+
+// IsInvalidTemplate asserts invalidTemplateError.
+func IsInvalidTemplate(err error) bool {
+	return microerror.Cause(err) == invalidTemplateError
+}
+
+var invalidValuesError = &microerror.Error{
+	Kind: "invalidValuesError",
+}
+
+// IsInvalidValues asserts invalidValuesError.
+func IsInvalidValues(err error) bool {
+	return microerror.Cause(err) == invalidValuesError
+}
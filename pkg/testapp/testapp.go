@@ -0,0 +1,195 @@
+// Package testapp renders the manifest of the test app, which is deployed to
+// tenant clusters to check ingress and availability under load.
+package testapp
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Template is the manifest template used unless another one is given. It
+// renders a Service, a PodDisruptionBudget, a Deployment and an Ingress, plus
+// a Namespace if the namespace isn't "default".
+//
+//go:embed manifest.yaml.tmpl
+var Template string
+
+// Values are passed to the manifest template.
+type Values struct {
+	// Name of all objects and the "app" label.
+	Name      string
+	Namespace string
+	// BaseDomain is the base domain of the cluster. The ingress is reachable
+	// at "test.<BaseDomain>".
+	BaseDomain string
+	Replicas   int
+	Image      string
+	// CPURequest and MemoryRequest are quantities like "3" and "1Gi". They
+	// are used as limits as well.
+	CPURequest    string
+	MemoryRequest string
+	IngressClass  string
+
+	// PodDisruptionBudgetAPIVersion is either "policy/v1" or
+	// "policy/v1beta1".
+	PodDisruptionBudgetAPIVersion string
+	// IngressAPIVersion is one of "networking.k8s.io/v1",
+	// "networking.k8s.io/v1beta1" and "extensions/v1beta1".
+	IngressAPIVersion string
+}
+
+// Supported API versions, newest first.
+var (
+	podDisruptionBudgetAPIVersions = []string{"policy/v1", "policy/v1beta1"}
+	ingressAPIVersions             = []string{"networking.k8s.io/v1", "networking.k8s.io/v1beta1", "extensions/v1beta1"}
+)
+
+// DefaultValues returns the values of the test app for a cluster with the
+// given base domain.
+func DefaultValues(baseDomain string) Values {
+	return Values{
+		Name:          "e2e-app",
+		Namespace:     "default",
+		BaseDomain:    baseDomain,
+		Replicas:      2,
+		Image:         "quay.io/giantswarm/e2e-app:latest",
+		CPURequest:    "3",
+		MemoryRequest: "10000000Ki",
+		IngressClass:  "nginx.ingress.kubernetes.io",
+
+		PodDisruptionBudgetAPIVersion: podDisruptionBudgetAPIVersions[0],
+		IngressAPIVersion:             ingressAPIVersions[0],
+	}
+}
+
+// WithServedAPIVersions returns a copy of the values using the newest API
+// versions served by the cluster, given in the form "group/version". If none
+// of the supported versions of a kind is served, the oldest one is used, for
+// the sake of clusters not reporting their versions properly.
+func (v Values) WithServedAPIVersions(served []string) Values {
+	v.PodDisruptionBudgetAPIVersion = newestServed(podDisruptionBudgetAPIVersions, served)
+	v.IngressAPIVersion = newestServed(ingressAPIVersions, served)
+
+	return v
+}
+
+func newestServed(supported []string, served []string) string {
+	for _, s := range supported {
+		for _, v := range served {
+			if s == v {
+				return s
+			}
+		}
+	}
+
+	return supported[len(supported)-1]
+}
+
+// BaseDomainFromAPIEndpoint returns the base domain of a cluster based on its
+// Kubernetes API endpoint, e.g. "abc12.k8s.example.com" for
+// "https://api.abc12.k8s.example.com".
+func BaseDomainFromAPIEndpoint(endpoint string) string {
+	return strings.Replace(endpoint, "https://api.", "", 1)
+}
+
+// Host returns the host name of the test app's ingress.
+func (v Values) Host() string {
+	return "test." + v.BaseDomain
+}
+
+func (v Values) validate() error {
+	var problems []string
+
+	required := []struct {
+		field string
+		value string
+	}{
+		{field: "Name", value: v.Name},
+		{field: "Namespace", value: v.Namespace},
+		{field: "BaseDomain", value: v.BaseDomain},
+		{field: "Image", value: v.Image},
+		{field: "IngressClass", value: v.IngressClass},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			problems = append(problems, fmt.Sprintf("%T.%s must not be empty", v, r.field))
+		}
+	}
+	if v.Replicas < 0 {
+		problems = append(problems, fmt.Sprintf("%T.Replicas must not be negative", v))
+	}
+	if !contains(podDisruptionBudgetAPIVersions, v.PodDisruptionBudgetAPIVersion) {
+		problems = append(problems, fmt.Sprintf("%T.PodDisruptionBudgetAPIVersion must be one of %s", v, strings.Join(podDisruptionBudgetAPIVersions, ", ")))
+	}
+	if !contains(ingressAPIVersions, v.IngressAPIVersion) {
+		problems = append(problems, fmt.Sprintf("%T.IngressAPIVersion must be one of %s", v, strings.Join(ingressAPIVersions, ", ")))
+	}
+
+	quantities := []struct {
+		field string
+		value string
+	}{
+		{field: "CPURequest", value: v.CPURequest},
+		{field: "MemoryRequest", value: v.MemoryRequest},
+	}
+	for _, q := range quantities {
+		_, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%T.%s %q is no valid quantity", v, q.field, q.value))
+		}
+	}
+
+	if len(problems) > 0 {
+		return microerror.Maskf(invalidValuesError, "%s", strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// LoadTemplate reads a manifest template from a file.
+func LoadTemplate(fileSystem afero.Fs, path string) (string, error) {
+	data, err := afero.ReadFile(fileSystem, path)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return string(data), nil
+}
+
+// Render renders the manifest template with the given values.
+func Render(manifestTemplate string, values Values) ([]byte, error) {
+	err := values.validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	t, err := template.New("manifest").Option("missingkey=error").Parse(manifestTemplate)
+	if err != nil {
+		return nil, microerror.Maskf(invalidTemplateError, "%s", err.Error())
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, values)
+	if err != nil {
+		return nil, microerror.Maskf(invalidTemplateError, "%s", err.Error())
+	}
+
+	return buf.Bytes(), nil
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,225 @@
+package testapp
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func Test_Render(t *testing.T) {
+	withNamespace := DefaultValues("abc12.k8s.example.com")
+	withNamespace.Namespace = "e2e"
+	withNamespace.Replicas = 4
+
+	invalid := DefaultValues("")
+	invalid.MemoryRequest = "lots"
+
+	legacy := DefaultValues("abc12.k8s.example.com").WithServedAPIVersions([]string{"v1", "policy/v1beta1", "extensions/v1beta1"})
+
+	unknownVersion := DefaultValues("abc12.k8s.example.com")
+	unknownVersion.IngressAPIVersion = "networking.k8s.io/v2"
+
+	testCases := []struct {
+		name              string
+		template          string
+		values            Values
+		expectedKinds     []string
+		expectedNamespace string
+		expectedReplicas  int64
+		expectedHost      string
+		expectedVersions  []string
+		expectedBackend   []string
+		errorMatcher      func(error) bool
+	}{
+		{
+			name:              "case 0: default template and values",
+			template:          Template,
+			values:            DefaultValues("abc12.k8s.example.com"),
+			expectedKinds:     []string{"Service", "PodDisruptionBudget", "Deployment", "Ingress"},
+			expectedNamespace: "default",
+			expectedReplicas:  2,
+			expectedHost:      "test.abc12.k8s.example.com",
+			expectedVersions:  []string{"v1", "policy/v1", "apps/v1", "networking.k8s.io/v1"},
+			expectedBackend:   []string{"backend", "service", "name"},
+		},
+		{
+			name:              "case 1: custom namespace gets created",
+			template:          Template,
+			values:            withNamespace,
+			expectedKinds:     []string{"Namespace", "Service", "PodDisruptionBudget", "Deployment", "Ingress"},
+			expectedNamespace: "e2e",
+			expectedReplicas:  4,
+			expectedHost:      "test.abc12.k8s.example.com",
+			expectedVersions:  []string{"v1", "v1", "policy/v1", "apps/v1", "networking.k8s.io/v1"},
+			expectedBackend:   []string{"backend", "service", "name"},
+		},
+		{
+			name:         "case 2: invalid values",
+			template:     Template,
+			values:       invalid,
+			errorMatcher: IsInvalidValues,
+		},
+		{
+			name:              "case 3: legacy API versions",
+			template:          Template,
+			values:            legacy,
+			expectedKinds:     []string{"Service", "PodDisruptionBudget", "Deployment", "Ingress"},
+			expectedNamespace: "default",
+			expectedReplicas:  2,
+			expectedHost:      "test.abc12.k8s.example.com",
+			expectedVersions:  []string{"v1", "policy/v1beta1", "apps/v1", "extensions/v1beta1"},
+			expectedBackend:   []string{"backend", "serviceName"},
+		},
+		{
+			name:         "case 4: unsupported API version",
+			template:     Template,
+			values:       unknownVersion,
+			errorMatcher: IsInvalidValues,
+		},
+		{
+			name:         "case 5: unknown value in template",
+			template:     "name: {{ .Unknown }}\n",
+			values:       DefaultValues("abc12.k8s.example.com"),
+			errorMatcher: IsInvalidTemplate,
+		},
+		{
+			name:         "case 6: template syntax error",
+			template:     "name: {{ .Name }\n",
+			values:       DefaultValues("abc12.k8s.example.com"),
+			errorMatcher: IsInvalidTemplate,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			manifest, err := Render(tc.template, tc.values)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("%s: error == nil, want non-nil", tc.name)
+			case !tc.errorMatcher(err):
+				t.Fatalf("%s: error == %#v, want matching", tc.name, err)
+			}
+
+			if tc.errorMatcher != nil {
+				return
+			}
+
+			objects := decode(t, manifest)
+
+			var kinds, versions []string
+			for _, o := range objects {
+				kinds = append(kinds, o.GetKind())
+				versions = append(versions, o.GetAPIVersion())
+				if o.GetKind() != "Namespace" && o.GetNamespace() != tc.expectedNamespace {
+					t.Fatalf("%s: namespace of %s == %q, want %q", tc.name, o.GetKind(), o.GetNamespace(), tc.expectedNamespace)
+				}
+
+				switch o.GetKind() {
+				case "Deployment":
+					// Numbers are decoded from JSON as float64.
+					replicas, _, _ := unstructured.NestedFloat64(o.Object, "spec", "replicas")
+					if int64(replicas) != tc.expectedReplicas {
+						t.Fatalf("%s: replicas == %f, want %d", tc.name, replicas, tc.expectedReplicas)
+					}
+				case "Ingress":
+					rules, _, _ := unstructured.NestedSlice(o.Object, "spec", "rules")
+					host, _, _ := unstructured.NestedString(rules[0].(map[string]interface{}), "host")
+					if host != tc.expectedHost {
+						t.Fatalf("%s: host == %q, want %q", tc.name, host, tc.expectedHost)
+					}
+					paths, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "http", "paths")
+					backend, _, _ := unstructured.NestedString(paths[0].(map[string]interface{}), tc.expectedBackend...)
+					if backend != tc.values.Name {
+						t.Fatalf("%s: backend service == %q, want %q", tc.name, backend, tc.values.Name)
+					}
+				}
+			}
+			if !cmp.Equal(kinds, tc.expectedKinds) {
+				t.Fatalf("%s: kinds == %v, want %v", tc.name, kinds, tc.expectedKinds)
+			}
+			if !cmp.Equal(versions, tc.expectedVersions) {
+				t.Fatalf("%s: API versions == %v, want %v", tc.name, versions, tc.expectedVersions)
+			}
+		})
+	}
+}
+
+func Test_Values_WithServedAPIVersions(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		served                 []string
+		expectedPDBVersion     string
+		expectedIngressVersion string
+	}{
+		{
+			name:                   "case 0: current versions",
+			served:                 []string{"v1", "policy/v1", "policy/v1beta1", "networking.k8s.io/v1", "networking.k8s.io/v1beta1"},
+			expectedPDBVersion:     "policy/v1",
+			expectedIngressVersion: "networking.k8s.io/v1",
+		},
+		{
+			name:                   "case 1: networking beta only",
+			served:                 []string{"v1", "policy/v1beta1", "networking.k8s.io/v1beta1", "extensions/v1beta1"},
+			expectedPDBVersion:     "policy/v1beta1",
+			expectedIngressVersion: "networking.k8s.io/v1beta1",
+		},
+		{
+			name:                   "case 2: nothing known falls back to the oldest versions",
+			served:                 []string{"v1"},
+			expectedPDBVersion:     "policy/v1beta1",
+			expectedIngressVersion: "extensions/v1beta1",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			values := DefaultValues("abc12.k8s.example.com").WithServedAPIVersions(tc.served)
+
+			if values.PodDisruptionBudgetAPIVersion != tc.expectedPDBVersion {
+				t.Fatalf("%s: PDB API version == %q, want %q", tc.name, values.PodDisruptionBudgetAPIVersion, tc.expectedPDBVersion)
+			}
+			if values.IngressAPIVersion != tc.expectedIngressVersion {
+				t.Fatalf("%s: Ingress API version == %q, want %q", tc.name, values.IngressAPIVersion, tc.expectedIngressVersion)
+			}
+		})
+	}
+}
+
+func Test_BaseDomainFromAPIEndpoint(t *testing.T) {
+	baseDomain := BaseDomainFromAPIEndpoint("https://api.abc12.k8s.example.com")
+	if baseDomain != "abc12.k8s.example.com" {
+		t.Fatalf("base domain == %q, want %q", baseDomain, "abc12.k8s.example.com")
+	}
+}
+
+func decode(t *testing.T, manifest []byte) []*unstructured.Unstructured {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+
+	var objects []*unstructured.Unstructured
+	for {
+		var content map[string]interface{}
+		err := decoder.Decode(&content)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid manifest: %s\n%s", err, manifest)
+		}
+		if len(content) == 0 {
+			continue
+		}
+
+		objects = append(objects, &unstructured.Unstructured{Object: content})
+	}
+
+	return objects
+}
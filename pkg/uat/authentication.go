@@ -0,0 +1,173 @@
+package uat
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/giantswarm/gsclientgen/client/clusters"
+	"github.com/giantswarm/gsclientgen/client/info"
+	"github.com/giantswarm/gsclientgen/client/node_pools"
+	"github.com/giantswarm/microerror"
+	"github.com/go-openapi/runtime"
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/client"
+	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+)
+
+// permissionDeniedCode is the error code the API documents for 401 responses.
+const permissionDeniedCode = "PERMISSION_DENIED"
+
+// TestAuthenticationFailures calls several endpoints with missing or invalid
+// credentials and verifies that the API rejects each request with status 401
+// and the documented error body.
+func TestAuthenticationFailures(giantSwarmClient *client.Client, clusterID string) error {
+	token, err := giantSwarmClient.GetToken()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	expiredToken, err := forgeToken(token, time.Now().Add(-1*time.Hour))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if !client.IsTokenExpired(expiredToken) {
+		return microerror.Maskf(assertionFailedError, "forged token is expected to be expired")
+	}
+
+	wrongKeyToken, err := forgeToken(token, time.Now().Add(1*time.Hour))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if client.IsTokenExpired(wrongKeyToken) {
+		return microerror.Maskf(assertionFailedError, "forged token is expected to be valid apart from its signature")
+	}
+
+	wrongScheme := "giantswarm"
+	if giantSwarmClient.AuthScheme == "giantswarm" {
+		wrongScheme = "Bearer"
+	}
+
+	authWriters := []struct {
+		name       string
+		authWriter runtime.ClientAuthInfoWriter
+	}{
+		{
+			name:       "no Authorization header",
+			authWriter: runtime.ClientAuthInfoWriterFunc(func(runtime.ClientRequest, strfmt.Registry) error { return nil }),
+		},
+		{
+			name:       "malformed Authorization header",
+			authWriter: authHeaderWriter("not-a-valid-header"),
+		},
+		{
+			name:       "expired token",
+			authWriter: authHeaderWriter(giantSwarmClient.AuthScheme + " " + expiredToken),
+		},
+		{
+			name:       "token signed by the wrong key",
+			authWriter: authHeaderWriter(giantSwarmClient.AuthScheme + " " + wrongKeyToken),
+		},
+		{
+			name:       "wrong scheme " + wrongScheme,
+			authWriter: authHeaderWriter(wrongScheme + " " + token),
+		},
+	}
+
+	endpoints := []struct {
+		name      string
+		operation func(runtime.ClientAuthInfoWriter) error
+	}{
+		{
+			name: "info",
+			operation: func(authWriter runtime.ClientAuthInfoWriter) error {
+				_, err := giantSwarmClient.GSClientGen.Info.GetInfo(info.NewGetInfoParams(), authWriter)
+				return err
+			},
+		},
+		{
+			name: "clusters",
+			operation: func(authWriter runtime.ClientAuthInfoWriter) error {
+				_, err := giantSwarmClient.GSClientGen.Clusters.GetClusters(clusters.NewGetClustersParams(), authWriter)
+				return err
+			},
+		},
+		{
+			name: "node_pools",
+			operation: func(authWriter runtime.ClientAuthInfoWriter) error {
+				params := node_pools.NewGetNodePoolsParams().WithClusterID(clusterID)
+				_, err := giantSwarmClient.GSClientGen.NodePools.GetNodePools(params, authWriter)
+				return err
+			},
+		},
+	}
+
+	failures := 0
+	for _, endpoint := range endpoints {
+		for _, a := range authWriters {
+			err := endpoint.operation(a.authWriter)
+			if err == nil {
+				failures++
+				cliutil.Complain(microerror.Maskf(assertionFailedError, "%s endpoint accepted a request with %s", endpoint.name, a.name))
+				continue
+			}
+
+			statusCode, body := responseStatus(err)
+			if statusCode != http.StatusUnauthorized {
+				failures++
+				cliutil.Complain(microerror.Maskf(assertionFailedError, "%s endpoint responded to a request with %s with status %d instead of 401: %s", endpoint.name, a.name, statusCode, err.Error()))
+				continue
+			}
+			if body == nil || body.Code != permissionDeniedCode || body.Message == "" {
+				failures++
+				cliutil.Complain(microerror.Maskf(assertionFailedError, "%s endpoint responded to a request with %s with an unexpected error body: %#v", endpoint.name, a.name, body))
+				continue
+			}
+
+			cliutil.PrintSuccess("%s endpoint rejected a request with %s", endpoint.name, a.name)
+		}
+	}
+
+	if failures > 0 {
+		return microerror.Maskf(assertionFailedError, "%d of %d requests with invalid credentials were not rejected as expected", failures, len(endpoints)*len(authWriters))
+	}
+
+	return nil
+}
+
+func authHeaderWriter(value string) runtime.ClientAuthInfoWriter {
+	return httptransport.APIKeyAuth("Authorization", "header", value)
+}
+
+// forgeToken creates a JWT carrying the claims of the given token, with the
+// given expiry, signed by a freshly generated key the API can't know.
+func forgeToken(token string, expiresAt time.Time) (string, error) {
+	claims := jwtgo.MapClaims{}
+
+	// If the token is not a JWT, we use a minimal set of claims instead.
+	_, _, err := new(jwtgo.Parser).ParseUnverified(token, claims)
+	if err != nil {
+		claims = jwtgo.MapClaims{
+			"sub": "api-acceptance-test",
+		}
+	}
+
+	claims["iat"] = expiresAt.Add(-1 * time.Hour).Unix()
+	claims["exp"] = expiresAt.Unix()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	signed, err := jwtgo.NewWithClaims(jwtgo.SigningMethodRS256, claims).SignedString(key)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return signed, nil
+}
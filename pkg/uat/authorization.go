@@ -0,0 +1,95 @@
+package uat
+
+import (
+	"net/http"
+
+	"github.com/giantswarm/gsclientgen/client/clusters"
+	"github.com/giantswarm/gsclientgen/client/key_pairs"
+	"github.com/giantswarm/gsclientgen/client/node_pools"
+	"github.com/giantswarm/gsclientgen/models"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/client"
+	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+)
+
+// TestAuthorizationBoundaries uses a client authenticated as a user who is not
+// a member of the cluster's owner organization to access and modify the given
+// cluster and node pool. Every attempt must be rejected with status 403 or 404.
+func TestAuthorizationBoundaries(unprivilegedClient *client.Client, clusterID string, nodePoolID string) error {
+	authWriter, err := unprivilegedClient.AuthHeaderWriter()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	attempts := []struct {
+		name      string
+		operation func() error
+	}{
+		{
+			name: "GetClusterV5",
+			operation: func() error {
+				params := clusters.NewGetClusterV5Params().WithClusterID(clusterID)
+				_, err := unprivilegedClient.GSClientGen.Clusters.GetClusterV5(params, authWriter)
+				return err
+			},
+		},
+		{
+			name: "ModifyNodePool",
+			operation: func() error {
+				body := &models.V5ModifyNodePoolRequest{
+					Name: "Renamed by unauthorized user",
+				}
+				params := node_pools.NewModifyNodePoolParams().WithClusterID(clusterID).WithNodepoolID(nodePoolID).WithBody(body)
+				_, err := unprivilegedClient.GSClientGen.NodePools.ModifyNodePool(params, authWriter)
+				return err
+			},
+		},
+		{
+			name: "AddKeyPair",
+			operation: func() error {
+				description := "key pair created by unauthorized user"
+				body := &models.V4AddKeyPairRequest{
+					TTLHours:    1,
+					Description: &description,
+				}
+				params := key_pairs.NewAddKeyPairParams().WithClusterID(clusterID).WithBody(body)
+				_, err := unprivilegedClient.GSClientGen.KeyPairs.AddKeyPair(params, authWriter)
+				return err
+			},
+		},
+		{
+			name: "DeleteCluster",
+			operation: func() error {
+				params := clusters.NewDeleteClusterParams().WithClusterID(clusterID)
+				_, err := unprivilegedClient.GSClientGen.Clusters.DeleteCluster(params, authWriter)
+				return err
+			},
+		},
+	}
+
+	failures := 0
+	for _, attempt := range attempts {
+		err := attempt.operation()
+		if err == nil {
+			failures++
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "%s on cluster %s succeeded for an unauthorized user", attempt.name, clusterID))
+			continue
+		}
+
+		statusCode, _ := responseStatus(err)
+		switch statusCode {
+		case http.StatusForbidden, http.StatusNotFound:
+			cliutil.PrintSuccess("%s was rejected with status %d", attempt.name, statusCode)
+		default:
+			failures++
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "%s was expected to fail with status 403 or 404, got %d: %s", attempt.name, statusCode, err.Error()))
+		}
+	}
+
+	if failures > 0 {
+		return microerror.Maskf(assertionFailedError, "%d of %d unauthorized requests were not rejected as expected", failures, len(attempts))
+	}
+
+	return nil
+}
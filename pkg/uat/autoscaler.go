@@ -0,0 +1,161 @@
+package uat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/client"
+	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+	"github.com/giantswarm/api-acceptance-test/pkg/k8s"
+)
+
+const autoscalerWorkloadTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: uat-autoscaler-workload
+  namespace: default
+  labels:
+    app: uat-autoscaler-workload
+spec:
+  replicas: %d
+  selector:
+    matchLabels:
+      app: uat-autoscaler-workload
+  template:
+    metadata:
+      labels:
+        app: uat-autoscaler-workload
+    spec:
+      nodeSelector:
+        %s: %s
+      containers:
+      - name: pause
+        image: k8s.gcr.io/pause:3.2
+        resources:
+          requests:
+            cpu: %dm
+`
+
+// NodeCountSample is a node count observed at some point in time.
+type NodeCountSample struct {
+	Elapsed    time.Duration
+	Nodes      int
+	ReadyNodes int
+}
+
+// TestClusterAutoscaler deploys a workload which requests more CPU than the
+// node pool currently provides and verifies that the node pool scales up to
+// its configured maximum, but not beyond. Then the workload is removed and
+// the node pool is expected to scale down to its minimum again. The observed
+// node counts are returned as a timeline.
+func TestClusterAutoscaler(giantSwarmClient *client.Client, k8sClient k8s.Interface, clusterID string, nodePoolID string, timeout time.Duration) ([]NodeCountSample, error) {
+	details, err := GetNodePoolDetails(giantSwarmClient, clusterID, nodePoolID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if details.Scaling == nil {
+		return nil, microerror.Maskf(assertionFailedError, "'scaling' is missing in node pool details")
+	}
+	min := int(details.Scaling.Min)
+	max := int(details.Scaling.Max)
+
+	selector := fmt.Sprintf("%s=%s", nodePoolLabel, nodePoolID)
+	ctx := context.Background()
+	nodes, err := k8sClient.ListNodes(ctx, selector)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if len(nodes) == 0 {
+		return nil, microerror.Maskf(assertionFailedError, "node pool %s has no nodes in the tenant cluster", nodePoolID)
+	}
+	if len(nodes) >= max {
+		return nil, microerror.Maskf(assertionFailedError, "node pool %s already has %d nodes, can't scale up beyond scaling.max=%d", nodePoolID, len(nodes), max)
+	}
+
+	allocatable := nodes[0].Status.Allocatable.Cpu().MilliValue()
+
+	// Each pod requests more than half of a node's CPU, so that every pod
+	// needs its own node and max pods need max nodes.
+	cpuRequest := allocatable * 6 / 10
+	manifest := []byte(fmt.Sprintf(autoscalerWorkloadTemplate, max, nodePoolLabel, nodePoolID, cpuRequest))
+
+	initialNodes := len(nodes)
+	cliutil.PrintInfo("Deploying %d pods requesting %dm CPU each on node pool %s with %d nodes (min=%d, max=%d)", max, cpuRequest, nodePoolID, initialNodes, min, max)
+
+	err = k8sClient.Apply(ctx, manifest)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var timeline []NodeCountSample
+	start := time.Now()
+
+	// Wait for scale up.
+	scaleUp := func(s NodeCountSample) bool { return s.ReadyNodes >= max }
+	timeline, err = watchNodeCount(k8sClient, selector, start, timeline, scaleUp, max, timeout)
+	if err != nil {
+		cliutil.Complain(k8sClient.Delete(ctx, manifest))
+		return timeline, microerror.Mask(err)
+	}
+	cliutil.PrintSuccess("Node pool %s scaled up from %d to %d nodes after %s", nodePoolID, initialNodes, max, timeline[len(timeline)-1].Elapsed)
+
+	// Remove workload and wait for scale down.
+	err = k8sClient.Delete(ctx, manifest)
+	if err != nil {
+		return timeline, microerror.Mask(err)
+	}
+	scaleDownStart := time.Now()
+
+	scaleDown := func(s NodeCountSample) bool { return s.Nodes <= min }
+	timeline, err = watchNodeCount(k8sClient, selector, start, timeline, scaleDown, max, timeout)
+	if err != nil {
+		return timeline, microerror.Mask(err)
+	}
+	cliutil.PrintSuccess("Node pool %s scaled down to %d nodes after %s", nodePoolID, min, time.Since(scaleDownStart))
+
+	return timeline, nil
+}
+
+// PrintNodeCountTimeline prints the given node count samples as a table.
+func PrintNodeCountTimeline(timeline []NodeCountSample) {
+	fmt.Printf("%12s %6s %6s\n", "ELAPSED", "NODES", "READY")
+	for _, s := range timeline {
+		fmt.Printf("%12s %6d %6d\n", s.Elapsed.Round(time.Second), s.Nodes, s.ReadyNodes)
+	}
+}
+
+// watchNodeCount samples the number of nodes matching the selector until the
+// given condition is met, appending each sample to the timeline. Node counts
+// above max and timeouts are reported as assertionFailedError.
+func watchNodeCount(k8sClient k8s.Interface, selector string, start time.Time, timeline []NodeCountSample, condition func(NodeCountSample) bool, max int, timeout time.Duration) ([]NodeCountSample, error) {
+	watchStart := time.Now()
+	for {
+		nodes, err := k8sClient.ListNodes(context.Background(), selector)
+		if err != nil {
+			return timeline, microerror.Mask(err)
+		}
+
+		sample := NodeCountSample{
+			Elapsed:    time.Since(start),
+			Nodes:      len(nodes),
+			ReadyNodes: k8s.CountReadyNodes(nodes),
+		}
+		timeline = append(timeline, sample)
+		cliutil.PrintInfo("%s: %d nodes, %d ready", sample.Elapsed.Round(time.Second), sample.Nodes, sample.ReadyNodes)
+
+		if sample.Nodes > max {
+			return timeline, microerror.Maskf(assertionFailedError, "node count %d exceeds scaling.max=%d", sample.Nodes, max)
+		}
+		if condition(sample) {
+			return timeline, nil
+		}
+		if time.Since(watchStart) > timeout {
+			return timeline, microerror.Maskf(assertionFailedError, "node count did not reach the expected value within %s", timeout)
+		}
+
+		time.Sleep(30 * time.Second)
+	}
+}
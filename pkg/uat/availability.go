@@ -0,0 +1,53 @@
+package uat
+
+import (
+	"time"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+	"github.com/giantswarm/api-acceptance-test/pkg/load"
+)
+
+// minAvailabilityWindow is the shortest period of time load statistics are
+// checked against the SLO for. Results are only recorded once a request
+// completes, so a step returning quickly could otherwise leave the window
+// without any results. It exceeds the default request timeout, so even
+// requests to an unresponsive app complete within the window.
+const minAvailabilityWindow = 15 * time.Second
+
+// CheckAvailability runs step while the generator's load continues and
+// checks the statistics of that period against the SLO. The period lasts at
+// least minAvailabilityWindow. Errors of the step take precedence over SLO
+// violations. If generator is nil, only the step is run.
+func CheckAvailability(generator *load.Generator, slo load.SLO, step func() error) error {
+	if generator == nil {
+		return step()
+	}
+
+	window, err := generator.StartWindow()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	start := time.Now()
+	stepErr := step()
+	if stepErr == nil {
+		time.Sleep(minAvailabilityWindow - time.Since(start))
+	}
+	stats := window.End()
+
+	cliutil.PrintInfo("Load on test app during step: %s", stats)
+
+	if stepErr != nil {
+		return microerror.Mask(stepErr)
+	}
+
+	err = slo.Check(stats)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cliutil.PrintSuccess("Test app availability met SLO (%s)", slo)
+	return nil
+}
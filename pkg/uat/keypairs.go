@@ -0,0 +1,221 @@
+package uat
+
+import (
+	"strings"
+	"time"
+
+	"github.com/giantswarm/gsclientgen/client/key_pairs"
+	"github.com/giantswarm/gsclientgen/models"
+	"github.com/giantswarm/microerror"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/certificate"
+	"github.com/giantswarm/api-acceptance-test/pkg/client"
+	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+	"github.com/giantswarm/api-acceptance-test/pkg/k8s"
+)
+
+// certificateExpiryTolerance is the accepted difference between a
+// certificate's NotAfter and the expiry expected from the key pair TTL.
+const certificateExpiryTolerance = 10 * time.Minute
+
+// keyPairExpiryGracePeriod is how long we wait beyond a key pair's expiry
+// before we expect its certificate to be rejected.
+const keyPairExpiryGracePeriod = 2 * time.Minute
+
+// ListKeyPairs returns all key pairs of a cluster.
+func ListKeyPairs(giantSwarmClient *client.Client, clusterID string) (models.V4GetKeyPairsResponse, error) {
+	params := key_pairs.NewGetKeyPairsParams().WithClusterID(clusterID)
+	authWriter, err := giantSwarmClient.AuthHeaderWriter()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	list, err := giantSwarmClient.GSClientGen.KeyPairs.GetKeyPairs(params, authWriter)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list.Payload, nil
+}
+
+// ExpiringKeyPair is a short-lived key pair created by TestKeyPairListing,
+// to be checked by TestKeyPairExpiry once it expired.
+type ExpiringKeyPair struct {
+	ID             string
+	KubeconfigPath string
+	// Expiry is when the key pair's kubeconfig is expected to be rejected,
+	// including a grace period.
+	Expiry time.Time
+}
+
+// TestKeyPairListing creates key pairs with different TTLs and descriptions
+// and verifies that the key pair list shows them with the correct details.
+// Returns the short-lived key pair, so that its expiry can be checked with
+// TestKeyPairExpiry later on, without waiting for it now.
+func TestKeyPairListing(giantSwarmClient *client.Client, clusterID string, clusterAPIEndpoint string) (*ExpiringKeyPair, error) {
+	specs := []KeyPairSpec{
+		{
+			Description:              "uat short-lived key pair",
+			TTLHours:                 1,
+			CertificateOrganizations: "system:masters",
+			CnPrefix:                 "short@giantswarm.io",
+		},
+		{
+			Description:              "uat key pair valid for a day",
+			TTLHours:                 24,
+			CertificateOrganizations: "system:masters",
+			CnPrefix:                 "day@giantswarm.io",
+		},
+		{
+			Description:              "uat key pair valid for a week",
+			TTLHours:                 24 * 7,
+			CertificateOrganizations: "system:masters",
+			CnPrefix:                 "week@giantswarm.io",
+		},
+	}
+
+	type created struct {
+		spec           KeyPairSpec
+		id             string
+		kubeconfigPath string
+		before         time.Time
+		after          time.Time
+	}
+	var keyPairs []created
+
+	for _, spec := range specs {
+		before := time.Now()
+		path, id, err := CreateKeyPairWithSpec(giantSwarmClient, clusterID, clusterAPIEndpoint, spec)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		keyPairs = append(keyPairs, created{
+			spec:           spec,
+			id:             id,
+			kubeconfigPath: path,
+			before:         before,
+			after:          time.Now(),
+		})
+	}
+
+	list, err := ListKeyPairs(giantSwarmClient, clusterID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, kp := range keyPairs {
+		var item *models.V4GetKeyPairsResponseItems
+		for _, i := range list {
+			if i.ID == kp.id {
+				item = i
+				break
+			}
+		}
+		if item == nil {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "key pair %s is missing in key pair list", kp.id))
+			continue
+		}
+
+		if item.Description != kp.spec.Description {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "'description' of key pair %s in list is %q, expected %q", kp.id, item.Description, kp.spec.Description))
+		}
+		if item.TTLHours != int64(kp.spec.TTLHours) {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "'ttl_hours' of key pair %s in list is %d, expected %d", kp.id, item.TTLHours, kp.spec.TTLHours))
+		}
+
+		createDate, err := time.Parse(time.RFC3339, item.CreateDate)
+		if err != nil {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "'create_date' of key pair %s in list can't be parsed: %s", kp.id, err.Error()))
+		} else if createDate.Before(kp.before.Add(-1*time.Minute)) || createDate.After(kp.after.Add(1*time.Minute)) {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "'create_date' of key pair %s in list is %s, expected between %s and %s", kp.id, createDate, kp.before, kp.after))
+		}
+	}
+	cliutil.PrintSuccess("Key pair list shows %d created key pairs", len(keyPairs))
+
+	shortLived := keyPairs[0]
+	expiring := &ExpiringKeyPair{
+		ID:             shortLived.id,
+		KubeconfigPath: shortLived.kubeconfigPath,
+		Expiry:         shortLived.after.Add(time.Duration(shortLived.spec.TTLHours) * time.Hour).Add(keyPairExpiryGracePeriod),
+	}
+
+	return expiring, nil
+}
+
+// TestKeyPairExpiry waits for the given key pair to expire, if it hasn't yet,
+// and checks that its kubeconfig is rejected as unauthorized.
+func TestKeyPairExpiry(newK8sClient k8s.Factory, keyPair *ExpiringKeyPair) error {
+	if wait := time.Until(keyPair.Expiry); wait > 0 {
+		cliutil.PrintInfo("Waiting %s until %s for key pair %s to expire", wait.Round(time.Second), keyPair.Expiry, keyPair.ID)
+		time.Sleep(wait)
+	}
+
+	k8sClient, err := newK8sClient(keyPair.KubeconfigPath)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = TestKeyPairAccess(k8sClient)
+	if err == nil {
+		return microerror.Maskf(assertionFailedError, "kubeconfig %s still grants access after key pair expiry", keyPair.KubeconfigPath)
+	} else if !k8s.IsUnauthorized(err) {
+		return microerror.Maskf(assertionFailedError, "kubeconfig %s failed with an unexpected error after key pair expiry, expected unauthorized: %s", keyPair.KubeconfigPath, err.Error())
+	}
+
+	cliutil.PrintSuccess("Kubeconfig %s no longer grants access after key pair expiry", keyPair.KubeconfigPath)
+	return nil
+}
+
+// validateKeyPairCertificates checks the certificates of a freshly created
+// key pair against the spec it was created with. Failures are reported, but
+// don't stop the test.
+func validateKeyPairCertificates(keyPair *models.V4AddKeyPairResponse, spec KeyPairSpec) {
+	cert, err := certificate.Parse(keyPair.ClientCertificateData)
+	if err != nil {
+		cliutil.Complain(microerror.Maskf(assertionFailedError, "'client_certificate_data' in key pair creation response can't be parsed: %s", err.Error()))
+		return
+	}
+
+	if !strings.HasPrefix(cert.Subject.CommonName, spec.CnPrefix) {
+		cliutil.Complain(microerror.Maskf(assertionFailedError, "certificate common name %q doesn't start with %q", cert.Subject.CommonName, spec.CnPrefix))
+	}
+
+	expectedOrgs := splitOrganizations(spec.CertificateOrganizations)
+	sortStrings := cmpopts.SortSlices(func(a, b string) bool { return a < b })
+	if !cmp.Equal(cert.Subject.Organization, expectedOrgs, sortStrings, cmpopts.EquateEmpty()) {
+		cliutil.Complain(microerror.Maskf(assertionFailedError, "certificate organizations differ from the request\n%s\n", cmp.Diff(expectedOrgs, cert.Subject.Organization, sortStrings, cmpopts.EquateEmpty())))
+	}
+
+	expectedNotAfter := time.Now().Add(time.Duration(spec.TTLHours) * time.Hour)
+	if diff := cert.NotAfter.Sub(expectedNotAfter); diff > certificateExpiryTolerance || diff < -certificateExpiryTolerance {
+		cliutil.Complain(microerror.Maskf(assertionFailedError, "certificate expires at %s, expected around %s for a TTL of %d hours", cert.NotAfter, expectedNotAfter, spec.TTLHours))
+	}
+
+	err = certificate.VerifyChain(cert, keyPair.CertificateAuthorityData, time.Now())
+	if err != nil {
+		cliutil.Complain(microerror.Maskf(assertionFailedError, "certificate doesn't verify against the returned CA: %s", err.Error()))
+	}
+
+	err = certificate.VerifyKeyPair(cert, keyPair.ClientKeyData)
+	if err != nil {
+		cliutil.Complain(microerror.Maskf(assertionFailedError, "private key doesn't match the certificate: %s", err.Error()))
+	}
+
+	cliutil.PrintInfo("Key pair certificate has CN %q, organizations %v and expires at %s", cert.Subject.CommonName, cert.Subject.Organization, cert.NotAfter)
+}
+
+// splitOrganizations splits the comma separated organizations of a key pair
+// request.
+func splitOrganizations(organizations string) []string {
+	var result []string
+	for _, o := range strings.Split(organizations, ",") {
+		o = strings.TrimSpace(o)
+		if o != "" {
+			result = append(result, o)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,394 @@
+package uat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/gsclientgen/client/info"
+	"github.com/giantswarm/gsclientgen/models"
+	"github.com/giantswarm/microerror"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/client"
+	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+	"github.com/giantswarm/api-acceptance-test/pkg/k8s"
+)
+
+// nodePoolLabel is the node label carrying the node pool ID.
+const nodePoolLabel = "giantswarm.io/machine-deployment"
+
+// TestMultipleNodePools creates one node pool per given instance type, each
+// in an explicitly chosen availability zone, and verifies that
+// - the node pools are created as requested,
+// - no two node pools in the cluster have overlapping subnets,
+// - the node pool list matches the details of each single node pool,
+// - the cluster stays intact while the node pools get deleted one by one.
+// The cluster is intact if it isn't being deleted and its latest condition is
+// a healthy one.
+func TestMultipleNodePools(giantSwarmClient *client.Client, clusterID string, instanceTypes []string) error {
+	authWriter, err := giantSwarmClient.AuthHeaderWriter()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	infoResponse, err := giantSwarmClient.GSClientGen.Info.GetInfo(info.NewGetInfoParams(), authWriter)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if infoResponse.Payload.General == nil || infoResponse.Payload.General.AvailabilityZones == nil || len(infoResponse.Payload.General.AvailabilityZones.Zones) == 0 {
+		return microerror.Maskf(assertionFailedError, "'general.availability_zones.zones' in info response is empty")
+	}
+	zones := infoResponse.Payload.General.AvailabilityZones.Zones
+
+	// Node pools not deleted explicitly, e.g. because of an early return, are
+	// deleted when leaving.
+	var nodePoolIDs []string
+	undeleted := map[string]bool{}
+	defer func() {
+		for _, id := range nodePoolIDs {
+			if undeleted[id] {
+				cliutil.Complain(DeleteNodePool(giantSwarmClient, clusterID, id))
+			}
+		}
+	}()
+
+	// Create node pools, spread over the available zones.
+	for i, instanceType := range instanceTypes {
+		zone := zones[i%len(zones)]
+
+		id, err := CreateNodePoolWithCustomParams(giantSwarmClient, clusterID, instanceType, []string{zone})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if id == "" {
+			return microerror.Maskf(assertionFailedError, "Node pool ID is missing in node pool creation response")
+		}
+
+		cliutil.PrintSuccess("Node pool %s created with instance type %s in zone %s", id, instanceType, zone)
+		nodePoolIDs = append(nodePoolIDs, id)
+		undeleted[id] = true
+	}
+
+	details := map[string]*models.V5GetNodePoolResponse{}
+	for i, id := range nodePoolIDs {
+		d, err := GetNodePoolDetails(giantSwarmClient, clusterID, id)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		details[id] = d
+
+		expectedZones := []string{zones[i%len(zones)]}
+		if !cmp.Equal(d.AvailabilityZones, expectedZones) {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "'availability_zones' of node pool %s differ from the request\n%s\n", id, cmp.Diff(expectedZones, d.AvailabilityZones)))
+		}
+		if d.NodeSpec == nil || d.NodeSpec.Aws == nil || d.NodeSpec.Aws.InstanceType != instanceTypes[i] {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "'node_spec.aws.instance_type' of node pool %s is not %s", id, instanceTypes[i]))
+		}
+	}
+
+	list, err := ListNodePools(giantSwarmClient, clusterID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Subnets of all node pools in the cluster must be disjoint.
+	for i := 0; i < len(list); i++ {
+		for j := i + 1; j < len(list); j++ {
+			overlap, err := subnetsOverlap(list[i].Subnet, list[j].Subnet)
+			if err != nil {
+				cliutil.Complain(microerror.Maskf(assertionFailedError, "could not compare subnets of node pools %s and %s: %s", list[i].ID, list[j].ID, err.Error()))
+			} else if overlap {
+				cliutil.Complain(microerror.Maskf(assertionFailedError, "subnet %s of node pool %s overlaps with subnet %s of node pool %s", list[i].Subnet, list[i].ID, list[j].Subnet, list[j].ID))
+			}
+		}
+	}
+
+	// The list must contain the same data as the single node pool details,
+	// apart from the status which may change between requests.
+	for _, id := range nodePoolIDs {
+		item := findNodePoolListItem(list, id)
+		if item == nil {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "node pool %s is missing in node pool list", id))
+			continue
+		}
+
+		listed, err := toNodePoolResponse(item)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		opt := cmpopts.IgnoreFields(models.V5GetNodePoolResponse{}, "Status")
+		if !cmp.Equal(listed, details[id], opt) {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "node pool %s in list differs from node pool details\n%s\n", id, cmp.Diff(details[id], listed, opt)))
+		}
+	}
+	cliutil.PrintSuccess("Node pool list is consistent with node pool details")
+
+	// Delete node pools one by one and check the cluster after each.
+	for i, id := range nodePoolIDs {
+		err = DeleteNodePool(giantSwarmClient, clusterID, id)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		delete(undeleted, id)
+
+		cluster, err := GetClusterDetails(giantSwarmClient, clusterID)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if cluster.DeleteDate != nil {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "cluster %s is being deleted after deleting node pool %s", clusterID, id))
+		}
+		condition := latestClusterCondition(cluster.Conditions)
+		if !isHealthyClusterCondition(condition) {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "cluster %s has condition %q after deleting node pool %s", clusterID, condition, id))
+		}
+
+		list, err := ListNodePools(giantSwarmClient, clusterID)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		for _, remaining := range nodePoolIDs[i+1:] {
+			if findNodePoolListItem(list, remaining) == nil {
+				cliutil.Complain(microerror.Maskf(assertionFailedError, "node pool %s vanished after deleting node pool %s", remaining, id))
+			}
+		}
+	}
+
+	return nil
+}
+
+// TestNodePoolListConsistency creates, scales, renames and deletes a node pool
+// and after each change waits for the node pool list to reflect it. Changes
+// not visible within the given timeout are reported as failures. The time each
+// change took to become visible is printed.
+func TestNodePoolListConsistency(giantSwarmClient *client.Client, clusterID string, timeout time.Duration) error {
+	nodePoolID, err := CreateNodePoolWithCustomParams(giantSwarmClient, clusterID, "", nil)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if nodePoolID == "" {
+		return microerror.Maskf(assertionFailedError, "Node pool ID is missing in node pool creation response")
+	}
+
+	// The node pool is deleted when leaving, unless the "delete" change
+	// below already did.
+	deleted := false
+	defer func() {
+		if !deleted {
+			cliutil.Complain(DeleteNodePool(giantSwarmClient, clusterID, nodePoolID))
+		}
+	}()
+
+	name := "List consistency test node pool"
+
+	changes := []struct {
+		name      string
+		change    func() error
+		condition func(models.V5GetNodePoolsResponse) bool
+	}{
+		{
+			name:   "create",
+			change: func() error { return nil },
+			condition: func(list models.V5GetNodePoolsResponse) bool {
+				return findNodePoolListItem(list, nodePoolID) != nil
+			},
+		},
+		{
+			name: "scale",
+			change: func() error {
+				return ScaleNodePool(giantSwarmClient, clusterID, nodePoolID, 1, 2)
+			},
+			condition: func(list models.V5GetNodePoolsResponse) bool {
+				item := findNodePoolListItem(list, nodePoolID)
+				return item != nil && item.Scaling != nil && item.Scaling.Min == 1 && item.Scaling.Max == 2
+			},
+		},
+		{
+			name: "rename",
+			change: func() error {
+				return RenameNodePool(giantSwarmClient, clusterID, nodePoolID, name)
+			},
+			condition: func(list models.V5GetNodePoolsResponse) bool {
+				item := findNodePoolListItem(list, nodePoolID)
+				return item != nil && item.Name == name
+			},
+		},
+		{
+			name: "delete",
+			change: func() error {
+				err := DeleteNodePool(giantSwarmClient, clusterID, nodePoolID)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+				deleted = true
+
+				return nil
+			},
+			condition: func(list models.V5GetNodePoolsResponse) bool {
+				return findNodePoolListItem(list, nodePoolID) == nil
+			},
+		},
+	}
+
+	failures := 0
+	for _, c := range changes {
+		err := c.change()
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		duration, err := waitForNodePoolList(giantSwarmClient, clusterID, timeout, c.condition)
+		if IsAssertionFailed(err) {
+			failures++
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "node pool list did not reflect %s of node pool %s within %s", c.name, nodePoolID, timeout))
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		cliutil.PrintSuccess("Node pool list reflected %s of node pool %s after %s", c.name, nodePoolID, duration)
+	}
+
+	if failures > 0 {
+		return microerror.Maskf(assertionFailedError, "%d of %d node pool changes were not reflected in the list in time", failures, len(changes))
+	}
+
+	return nil
+}
+
+// TestNodePoolScaling scales a node pool to the given min/max and waits until
+// both the node pool status and the nodes in the tenant cluster converge to
+// a node count within these limits. Convergence means that all nodes the API
+// reports are ready and the same number of ready nodes carrying the node pool
+// label exist in the tenant cluster. Returns the time it took to converge.
+func TestNodePoolScaling(giantSwarmClient *client.Client, k8sClient k8s.Interface, clusterID string, nodePoolID string, min int, max int, timeout time.Duration) (time.Duration, error) {
+	err := ScaleNodePool(giantSwarmClient, clusterID, nodePoolID, min, max)
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	selector := fmt.Sprintf("%s=%s", nodePoolLabel, nodePoolID)
+
+	start := time.Now()
+	for {
+		details, err := GetNodePoolDetails(giantSwarmClient, clusterID, nodePoolID)
+		if err != nil {
+			return time.Since(start), microerror.Mask(err)
+		}
+
+		nodes, err := k8sClient.ListNodes(context.Background(), selector)
+		if err != nil {
+			return time.Since(start), microerror.Mask(err)
+		}
+		readyNodes := k8s.CountReadyNodes(nodes)
+
+		var apiNodes, apiNodesReady int64
+		if details.Status != nil {
+			apiNodes = details.Status.Nodes
+			apiNodesReady = details.Status.NodesReady
+		}
+
+		cliutil.PrintInfo("%s: status.nodes=%d, status.nodes_ready=%d, ready nodes in cluster=%d", time.Since(start).Round(time.Second), apiNodes, apiNodesReady, readyNodes)
+
+		converged := apiNodesReady >= int64(min) &&
+			apiNodesReady <= int64(max) &&
+			apiNodes == apiNodesReady &&
+			int64(readyNodes) == apiNodesReady
+		if converged {
+			duration := time.Since(start)
+			cliutil.PrintSuccess("Node pool %s/%s converged to %d nodes after %s", clusterID, nodePoolID, readyNodes, duration)
+			return duration, nil
+		}
+
+		if time.Since(start) > timeout {
+			return time.Since(start), microerror.Maskf(assertionFailedError, "node pool %s/%s did not converge to min=%d/max=%d within %s", clusterID, nodePoolID, min, max, timeout)
+		}
+
+		time.Sleep(15 * time.Second)
+	}
+}
+
+// waitForNodePoolList lists node pools until the given condition is met and
+// returns how long that took. If the condition isn't met within the timeout,
+// an assertionFailedError is returned.
+func waitForNodePoolList(giantSwarmClient *client.Client, clusterID string, timeout time.Duration, condition func(models.V5GetNodePoolsResponse) bool) (time.Duration, error) {
+	start := time.Now()
+	for {
+		list, err := ListNodePools(giantSwarmClient, clusterID)
+		if err != nil {
+			return time.Since(start), microerror.Mask(err)
+		}
+		if condition(list) {
+			return time.Since(start), nil
+		}
+		if time.Since(start) > timeout {
+			return time.Since(start), microerror.Maskf(assertionFailedError, "condition not met within %s", timeout)
+		}
+
+		time.Sleep(1 * time.Second)
+	}
+}
+
+// latestClusterCondition returns the condition with the latest transition
+// time, or an empty string if there is none.
+func latestClusterCondition(conditions []*models.V5ClusterDetailsResponseConditionsItems) string {
+	var latest string
+	var latestTime time.Time
+	for _, c := range conditions {
+		if c == nil {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, c.LastTransitionTime)
+		if err != nil {
+			continue
+		}
+		if latest == "" || t.After(latestTime) {
+			latest = c.Condition
+			latestTime = t
+		}
+	}
+
+	return latest
+}
+
+// isHealthyClusterCondition returns true for conditions of a cluster which is
+// usable and neither being deleted nor failed, e.g. "Created" or "Updating".
+func isHealthyClusterCondition(condition string) bool {
+	switch condition {
+	case "Created", "Updating", "Updated":
+		return true
+	default:
+		return false
+	}
+}
+
+func findNodePoolListItem(list models.V5GetNodePoolsResponse, id string) *models.V5GetNodePoolsResponseItems {
+	for _, item := range list {
+		if item.ID == id {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// toNodePoolResponse converts a node pool list item into the type returned
+// for a single node pool, so both can be compared. They share the same schema.
+func toNodePoolResponse(item *models.V5GetNodePoolsResponseItems) (*models.V5GetNodePoolResponse, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	response := &models.V5GetNodePoolResponse{}
+	err = json.Unmarshal(data, response)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return response, nil
+}
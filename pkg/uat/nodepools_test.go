@@ -0,0 +1,64 @@
+package uat
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/gsclientgen/models"
+)
+
+func Test_latestClusterCondition(t *testing.T) {
+	testCases := []struct {
+		name       string
+		conditions []*models.V5ClusterDetailsResponseConditionsItems
+		expected   string
+		healthy    bool
+	}{
+		{
+			name:     "case 0: no conditions",
+			expected: "",
+			healthy:  false,
+		},
+		{
+			name: "case 1: created",
+			conditions: []*models.V5ClusterDetailsResponseConditionsItems{
+				{Condition: "Creating", LastTransitionTime: "2020-05-07T10:00:00Z"},
+				{Condition: "Created", LastTransitionTime: "2020-05-07T10:20:00Z"},
+			},
+			expected: "Created",
+			healthy:  true,
+		},
+		{
+			name: "case 2: deleting, newest first",
+			conditions: []*models.V5ClusterDetailsResponseConditionsItems{
+				{Condition: "Deleting", LastTransitionTime: "2020-05-07T11:00:00Z"},
+				{Condition: "Created", LastTransitionTime: "2020-05-07T10:20:00Z"},
+				{Condition: "Creating", LastTransitionTime: "2020-05-07T10:00:00Z"},
+			},
+			expected: "Deleting",
+			healthy:  false,
+		},
+		{
+			name: "case 3: unparseable transition time is ignored",
+			conditions: []*models.V5ClusterDetailsResponseConditionsItems{
+				{Condition: "Updated", LastTransitionTime: "2020-05-07T10:20:00Z"},
+				nil,
+				{Condition: "Deleting", LastTransitionTime: "soon"},
+			},
+			expected: "Updated",
+			healthy:  true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			condition := latestClusterCondition(tc.conditions)
+			if condition != tc.expected {
+				t.Fatalf("%s: condition == %q, want %q", tc.name, condition, tc.expected)
+			}
+			if healthy := isHealthyClusterCondition(condition); healthy != tc.healthy {
+				t.Fatalf("%s: healthy == %v, want %v", tc.name, healthy, tc.healthy)
+			}
+		})
+	}
+}
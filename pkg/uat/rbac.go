@@ -0,0 +1,116 @@
+package uat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	authorizationv1 "k8s.io/api/authorization/v1"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/client"
+	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+	"github.com/giantswarm/api-acceptance-test/pkg/k8s"
+)
+
+const restrictedRBACTemplate = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: uat-pod-reader
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["get", "list"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: uat-pod-reader
+subjects:
+- kind: Group
+  name: %s
+  apiGroup: rbac.authorization.k8s.io
+roleRef:
+  kind: ClusterRole
+  name: uat-pod-reader
+  apiGroup: rbac.authorization.k8s.io
+`
+
+// TestKeyPairOrganizationRBAC creates a key pair with a custom certificate
+// organization, binds a read-only ClusterRole to the group of that name and
+// verifies via SelfSubjectAccessReview that the key pair's user has exactly
+// these permissions. This proves that the organization ends up as the
+// user's group in the tenant cluster.
+func TestKeyPairOrganizationRBAC(giantSwarmClient *client.Client, adminK8sClient k8s.Interface, newK8sClient k8s.Factory, clusterID string, clusterAPIEndpoint string) error {
+	group := fmt.Sprintf("uat-restricted-%d", time.Now().Unix())
+
+	spec := KeyPairSpec{
+		Description:              "uat restricted key pair",
+		TTLHours:                 1,
+		CertificateOrganizations: group,
+		CnPrefix:                 "restricted@giantswarm.io",
+	}
+	restrictedKubeconfigPath, _, err := CreateKeyPairWithSpec(giantSwarmClient, clusterID, clusterAPIEndpoint, spec)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	restrictedK8sClient, err := newK8sClient(restrictedKubeconfigPath)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	ctx := context.Background()
+	manifest := []byte(fmt.Sprintf(restrictedRBACTemplate, group))
+	err = adminK8sClient.Apply(ctx, manifest)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer func() {
+		cliutil.Complain(adminK8sClient.Delete(ctx, manifest))
+	}()
+
+	testCases := []struct {
+		attributes authorizationv1.ResourceAttributes
+		allowed    bool
+	}{
+		{attributes: authorizationv1.ResourceAttributes{Verb: "list", Resource: "pods"}, allowed: true},
+		{attributes: authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods"}, allowed: true},
+		{attributes: authorizationv1.ResourceAttributes{Verb: "delete", Resource: "pods"}, allowed: false},
+		{attributes: authorizationv1.ResourceAttributes{Verb: "list", Resource: "secrets"}, allowed: false},
+		{attributes: authorizationv1.ResourceAttributes{Verb: "create", Group: "apps", Resource: "deployments"}, allowed: false},
+	}
+
+	failures := 0
+	for _, tc := range testCases {
+		var allowed bool
+
+		// The binding may take a moment to become effective, so we retry
+		// while the result differs from the expectation.
+		start := time.Now()
+		for {
+			allowed, err = restrictedK8sClient.CanI(ctx, tc.attributes)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+			if allowed == tc.allowed || time.Since(start) > 1*time.Minute {
+				break
+			}
+			time.Sleep(5 * time.Second)
+		}
+
+		if allowed != tc.allowed {
+			failures++
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "group %s: %s %s allowed=%v, expected %v", group, tc.attributes.Verb, tc.attributes.Resource, allowed, tc.allowed))
+			continue
+		}
+
+		cliutil.PrintSuccess("Group %s: %s %s allowed=%v as expected", group, tc.attributes.Verb, tc.attributes.Resource, allowed)
+	}
+
+	if failures > 0 {
+		return microerror.Maskf(assertionFailedError, "%d of %d access reviews didn't match the expectation", failures, len(testCases))
+	}
+
+	return nil
+}
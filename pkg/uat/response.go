@@ -0,0 +1,109 @@
+package uat
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/giantswarm/gsclientgen/client/clusters"
+	"github.com/giantswarm/gsclientgen/client/info"
+	"github.com/giantswarm/gsclientgen/client/key_pairs"
+	"github.com/giantswarm/gsclientgen/client/node_pools"
+	"github.com/giantswarm/gsclientgen/models"
+	"github.com/giantswarm/microerror"
+	"github.com/go-openapi/runtime"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/client"
+)
+
+// responseStatus returns the HTTP status code and the error body of an
+// error returned by a gsclientgen operation or by client.DoRawRequest.
+// If the error doesn't carry a response, the status code is 0 and the
+// body is nil.
+func responseStatus(err error) (int, *models.V4GenericResponse) {
+	switch e := microerror.Cause(err).(type) {
+	case *info.GetInfoUnauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *info.GetInfoDefault:
+		return e.Code(), e.Payload
+
+	case *clusters.GetClustersUnauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *clusters.GetClustersDefault:
+		return e.Code(), e.Payload
+	case *clusters.GetClusterV5Unauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *clusters.GetClusterV5NotFound:
+		return http.StatusNotFound, e.Payload
+	case *clusters.GetClusterV5Default:
+		return e.Code(), e.Payload
+	case *clusters.AddClusterV5BadRequest:
+		return http.StatusBadRequest, e.Payload
+	case *clusters.AddClusterV5Unauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *clusters.AddClusterV5Default:
+		return e.Code(), e.Payload
+	case *clusters.DeleteClusterUnauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *clusters.DeleteClusterNotFound:
+		return http.StatusNotFound, e.Payload
+	case *clusters.DeleteClusterDefault:
+		return e.Code(), e.Payload
+
+	case *node_pools.AddNodePoolBadRequest:
+		return http.StatusBadRequest, e.Payload
+	case *node_pools.AddNodePoolUnauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *node_pools.AddNodePoolNotFound:
+		return http.StatusNotFound, e.Payload
+	case *node_pools.AddNodePoolDefault:
+		return e.Code(), e.Payload
+	case *node_pools.GetNodePoolUnauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *node_pools.GetNodePoolNotFound:
+		return http.StatusNotFound, e.Payload
+	case *node_pools.GetNodePoolDefault:
+		return e.Code(), e.Payload
+	case *node_pools.GetNodePoolsUnauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *node_pools.GetNodePoolsNotFound:
+		return http.StatusNotFound, e.Payload
+	case *node_pools.GetNodePoolsDefault:
+		return e.Code(), e.Payload
+	case *node_pools.ModifyNodePoolUnauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *node_pools.ModifyNodePoolNotFound:
+		return http.StatusNotFound, e.Payload
+	case *node_pools.ModifyNodePoolDefault:
+		return e.Code(), e.Payload
+	case *node_pools.DeleteNodePoolUnauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *node_pools.DeleteNodePoolNotFound:
+		return http.StatusNotFound, e.Payload
+	case *node_pools.DeleteNodePoolDefault:
+		return e.Code(), e.Payload
+
+	case *key_pairs.AddKeyPairUnauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *key_pairs.AddKeyPairServiceUnavailable:
+		return http.StatusServiceUnavailable, e.Payload
+	case *key_pairs.AddKeyPairDefault:
+		return e.Code(), e.Payload
+	case *key_pairs.GetKeyPairsUnauthorized:
+		return http.StatusUnauthorized, e.Payload
+	case *key_pairs.GetKeyPairsDefault:
+		return e.Code(), e.Payload
+
+	case *runtime.APIError:
+		return e.Code, nil
+
+	case *client.ResponseError:
+		body := &models.V4GenericResponse{}
+		err := json.Unmarshal(e.Body, body)
+		if err != nil {
+			return e.StatusCode, nil
+		}
+		return e.StatusCode, body
+	}
+
+	return 0, nil
+}
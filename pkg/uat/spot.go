@@ -0,0 +1,174 @@
+package uat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/gsclientgen/client/node_pools"
+	"github.com/giantswarm/gsclientgen/models"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/client"
+	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+	"github.com/giantswarm/api-acceptance-test/pkg/k8s"
+)
+
+const (
+	// lifecycleLabel is the node label telling spot and on-demand nodes apart.
+	lifecycleLabel = "node.kubernetes.io/lifecycle"
+
+	lifecycleOnDemand = "on-demand"
+	lifecycleSpot     = "spot"
+)
+
+// InstanceDistribution defines the mix of on-demand and spot instances in an
+// AWS node pool.
+type InstanceDistribution struct {
+	OnDemandBaseCapacity                int64
+	OnDemandPercentageAboveBaseCapacity int64
+	UseAlikeInstanceTypes               bool
+}
+
+// CreateNodePoolWithInstanceDistribution creates a node pool with a fixed
+// number of nodes and the given instance distribution, and checks that the
+// creation response echoes the distribution.
+func CreateNodePoolWithInstanceDistribution(giantSwarmClient *client.Client, clusterID string, nodes int64, distribution InstanceDistribution) (string, error) {
+	req := &models.V5AddNodePoolRequest{
+		Name: fmt.Sprintf("Spot %d+%d%%", distribution.OnDemandBaseCapacity, distribution.OnDemandPercentageAboveBaseCapacity),
+		Scaling: &models.V5AddNodePoolRequestScaling{
+			Min: nodes,
+			Max: nodes,
+		},
+		NodeSpec: &models.V5AddNodePoolRequestNodeSpec{
+			Aws: &models.V5AddNodePoolRequestNodeSpecAws{
+				InstanceDistribution: &models.V5AddNodePoolRequestNodeSpecAwsInstanceDistribution{
+					OnDemandBaseCapacity:                &distribution.OnDemandBaseCapacity,
+					OnDemandPercentageAboveBaseCapacity: &distribution.OnDemandPercentageAboveBaseCapacity,
+				},
+				UseAlikeInstanceTypes: &distribution.UseAlikeInstanceTypes,
+			},
+		},
+	}
+
+	params := node_pools.NewAddNodePoolParams().WithClusterID(clusterID).WithBody(req)
+	authWriter, err := giantSwarmClient.AuthHeaderWriter()
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	creationResult, err := giantSwarmClient.GSClientGen.NodePools.AddNodePool(params, authWriter)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if creationResult.Payload.NodeSpec == nil || creationResult.Payload.NodeSpec.Aws == nil {
+		cliutil.Complain(microerror.Maskf(assertionFailedError, "'node_spec.aws' is missing in node pool creation response"))
+	} else {
+		aws := creationResult.Payload.NodeSpec.Aws
+		if aws.InstanceDistribution == nil {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "'node_spec.aws.instance_distribution' is missing in node pool creation response"))
+		} else {
+			if aws.InstanceDistribution.OnDemandBaseCapacity != distribution.OnDemandBaseCapacity {
+				cliutil.Complain(microerror.Maskf(assertionFailedError, "'node_spec.aws.instance_distribution.on_demand_base_capacity' in node pool creation response is not %d", distribution.OnDemandBaseCapacity))
+			}
+			if aws.InstanceDistribution.OnDemandPercentageAboveBaseCapacity != distribution.OnDemandPercentageAboveBaseCapacity {
+				cliutil.Complain(microerror.Maskf(assertionFailedError, "'node_spec.aws.instance_distribution.on_demand_percentage_above_base_capacity' in node pool creation response is not %d", distribution.OnDemandPercentageAboveBaseCapacity))
+			}
+		}
+		if aws.UseAlikeInstanceTypes != distribution.UseAlikeInstanceTypes {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "'node_spec.aws.use_alike_instance_types' in node pool creation response is not %v", distribution.UseAlikeInstanceTypes))
+		}
+	}
+
+	if creationResult.Payload.ID == "" {
+		// we can't continue without this
+		return "", microerror.Maskf(assertionFailedError, "Node pool ID is missing in node pool creation response")
+	}
+
+	cliutil.PrintSuccess("Node pool %s created with instance distribution %+v", creationResult.Payload.ID, distribution)
+	return creationResult.Payload.ID, nil
+}
+
+// TestSpotInstanceMix creates a node pool with the given instance
+// distribution, waits for its nodes to become ready and verifies via node
+// labels that the expected numbers of on-demand and spot nodes are running.
+// The node pool is deleted afterwards.
+func TestSpotInstanceMix(giantSwarmClient *client.Client, k8sClient k8s.Interface, clusterID string, nodes int64, distribution InstanceDistribution, timeout time.Duration) error {
+	nodePoolID, err := CreateNodePoolWithInstanceDistribution(giantSwarmClient, clusterID, nodes, distribution)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer func() {
+		cliutil.Complain(DeleteNodePool(giantSwarmClient, clusterID, nodePoolID))
+	}()
+
+	selector := fmt.Sprintf("%s=%s", nodePoolLabel, nodePoolID)
+
+	start := time.Now()
+	var readyNodes []corev1.Node
+	for {
+		all, err := k8sClient.ListNodes(context.Background(), selector)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		readyNodes = nil
+		for _, n := range all {
+			if k8s.IsNodeReady(n) {
+				readyNodes = append(readyNodes, n)
+			}
+		}
+		if int64(len(readyNodes)) >= nodes {
+			break
+		}
+		if time.Since(start) > timeout {
+			return microerror.Maskf(assertionFailedError, "node pool %s has %d of %d nodes ready after %s", nodePoolID, len(readyNodes), nodes, timeout)
+		}
+
+		time.Sleep(30 * time.Second)
+	}
+
+	var onDemand, spot int64
+	for _, n := range readyNodes {
+		switch n.Labels[lifecycleLabel] {
+		case lifecycleOnDemand:
+			onDemand++
+		case lifecycleSpot:
+			spot++
+		default:
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "node %s has unexpected %s label %q", n.Name, lifecycleLabel, n.Labels[lifecycleLabel]))
+		}
+	}
+
+	expectedOnDemand := expectedOnDemandNodes(int64(len(readyNodes)), distribution)
+	expectedSpot := int64(len(readyNodes)) - expectedOnDemand
+	if onDemand != expectedOnDemand || spot != expectedSpot {
+		return microerror.Maskf(assertionFailedError, "node pool %s runs %d on-demand and %d spot nodes, expected %d and %d", nodePoolID, onDemand, spot, expectedOnDemand, expectedSpot)
+	}
+
+	details, err := GetNodePoolDetails(giantSwarmClient, clusterID, nodePoolID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if details.Status == nil || details.Status.SpotInstances != expectedSpot {
+		cliutil.Complain(microerror.Maskf(assertionFailedError, "'status.spot_instances' of node pool %s is not %d", nodePoolID, expectedSpot))
+	}
+
+	cliutil.PrintSuccess("Node pool %s runs %d on-demand and %d spot nodes as expected", nodePoolID, onDemand, spot)
+	return nil
+}
+
+// expectedOnDemandNodes returns how many of the given number of nodes are
+// on-demand instances according to the distribution. Like AWS, it rounds
+// the on-demand share above the base capacity up.
+func expectedOnDemandNodes(nodes int64, distribution InstanceDistribution) int64 {
+	if nodes <= distribution.OnDemandBaseCapacity {
+		return nodes
+	}
+
+	aboveBase := nodes - distribution.OnDemandBaseCapacity
+	onDemandAboveBase := (aboveBase*distribution.OnDemandPercentageAboveBaseCapacity + 99) / 100
+
+	return distribution.OnDemandBaseCapacity + onDemandAboveBase
+}
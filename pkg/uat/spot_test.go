@@ -0,0 +1,49 @@
+package uat
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_expectedOnDemandNodes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		nodes        int64
+		distribution InstanceDistribution
+		expected     int64
+	}{
+		{
+			name:         "case 0: spot only",
+			nodes:        3,
+			distribution: InstanceDistribution{OnDemandBaseCapacity: 0, OnDemandPercentageAboveBaseCapacity: 0},
+			expected:     0,
+		},
+		{
+			name:         "case 1: on-demand only",
+			nodes:        3,
+			distribution: InstanceDistribution{OnDemandBaseCapacity: 0, OnDemandPercentageAboveBaseCapacity: 100},
+			expected:     3,
+		},
+		{
+			name:         "case 2: base capacity and half above, rounded up",
+			nodes:        4,
+			distribution: InstanceDistribution{OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 50},
+			expected:     3,
+		},
+		{
+			name:         "case 3: base capacity exceeds nodes",
+			nodes:        2,
+			distribution: InstanceDistribution{OnDemandBaseCapacity: 3, OnDemandPercentageAboveBaseCapacity: 0},
+			expected:     2,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			onDemand := expectedOnDemandNodes(tc.nodes, tc.distribution)
+			if onDemand != tc.expected {
+				t.Fatalf("%s: expected %d, got %d", tc.name, tc.expected, onDemand)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package uat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/afero"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/client"
+	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+	"github.com/giantswarm/api-acceptance-test/pkg/k8s"
+	"github.com/giantswarm/api-acceptance-test/pkg/kubeconfig"
+)
+
+// TestSSOKubeconfigs writes kubeconfig files authenticating with the SSO ID
+// token of the client, once as a static token, once via the oidc auth
+// provider and once via an exec credential plugin. Each one is expected to
+// grant access to the cluster's Kubernetes API. Server and CA are taken from
+// the given key pair kubeconfig. As they contain the ID token, all files are
+// written to a temporary directory which is removed when leaving.
+func TestSSOKubeconfigs(giantSwarmClient *client.Client, newK8sClient k8s.Factory, clusterID string, keyPairKubeconfigPath string) error {
+	idToken := giantSwarmClient.RawIDToken
+	if idToken == "" {
+		return microerror.Maskf(assertionFailedError, "client has no SSO ID token")
+	}
+
+	issuerURL, clientID, err := idTokenIssuer(idToken)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	fs := afero.NewOsFs()
+
+	kc, err := kubeconfig.Load(fs, keyPairKubeconfigPath)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cluster, err := kubeconfig.CurrentCluster(kc)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	server := cluster.Server
+	caData := cluster.CertificateAuthorityData
+
+	dir, err := afero.TempDir(fs, "", fmt.Sprintf("uat-%s-sso-", clusterID))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer func() {
+		cliutil.Complain(fs.RemoveAll(dir))
+	}()
+
+	credentialPath := filepath.Join(dir, "exec-credential.json")
+	err = writeExecCredential(fs, credentialPath, idToken)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	testCases := []struct {
+		name string
+		user *clientcmdapi.AuthInfo
+	}{
+		{
+			name: "token",
+			user: kubeconfig.TokenUser(idToken),
+		},
+		{
+			name: "oidc",
+			user: kubeconfig.OIDCUser(issuerURL, clientID, idToken, ""),
+		},
+		{
+			name: "exec",
+			user: kubeconfig.ExecUser("cat", []string{credentialPath}, nil),
+		},
+	}
+
+	failures := 0
+	for _, tc := range testCases {
+		path := filepath.Join(dir, fmt.Sprintf("kubeconfig_%s.yaml", tc.name))
+		err = kubeconfig.WriteKubeconfigFileForUser(fs, path, server, string(caData), tc.user)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		k8sClient, err := newK8sClient(path)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		nodes, err := k8sClient.ListNodes(context.Background(), "")
+		if err != nil {
+			failures++
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "listing nodes with %s user from %s failed: %s", tc.name, path, err.Error()))
+			continue
+		}
+
+		cliutil.PrintSuccess("Listed %d nodes with %s user from %s", len(nodes), tc.name, path)
+	}
+
+	if failures > 0 {
+		return microerror.Maskf(assertionFailedError, "%d of %d SSO kubeconfigs didn't grant access", failures, len(testCases))
+	}
+
+	return nil
+}
+
+// idTokenIssuer returns the issuer URL and the client ID (audience) of the
+// given ID token, without verifying it.
+func idTokenIssuer(idToken string) (string, string, error) {
+	claims := jwtgo.MapClaims{}
+	_, _, err := new(jwtgo.Parser).ParseUnverified(idToken, claims)
+	if err != nil {
+		return "", "", microerror.Mask(err)
+	}
+
+	issuerURL, _ := claims["iss"].(string)
+	if issuerURL == "" {
+		return "", "", microerror.Maskf(assertionFailedError, "ID token has no 'iss' claim")
+	}
+
+	var clientID string
+	switch aud := claims["aud"].(type) {
+	case string:
+		clientID = aud
+	case []interface{}:
+		if len(aud) > 0 {
+			clientID, _ = aud[0].(string)
+		}
+	}
+	if clientID == "" {
+		return "", "", microerror.Maskf(assertionFailedError, "ID token has no 'aud' claim")
+	}
+
+	return issuerURL, clientID, nil
+}
+
+// writeExecCredential writes an ExecCredential object holding the token, to
+// be printed by an exec credential plugin.
+func writeExecCredential(fs afero.Fs, path string, token string) error {
+	credential := map[string]interface{}{
+		"apiVersion": kubeconfig.ExecAPIVersion,
+		"kind":       "ExecCredential",
+		"status": map[string]interface{}{
+			"token": token,
+		},
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = afero.WriteFile(fs, path, data, 0600)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
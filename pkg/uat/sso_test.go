@@ -0,0 +1,59 @@
+package uat
+
+import (
+	"strconv"
+	"testing"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+)
+
+func Test_idTokenIssuer(t *testing.T) {
+	testCases := []struct {
+		name             string
+		claims           jwtgo.MapClaims
+		expectedIssuer   string
+		expectedClientID string
+		expectedError    bool
+	}{
+		{
+			name:             "case 0: audience as string",
+			claims:           jwtgo.MapClaims{"iss": "https://issuer.example.com/", "aud": "client"},
+			expectedIssuer:   "https://issuer.example.com/",
+			expectedClientID: "client",
+		},
+		{
+			name:             "case 1: audience as list",
+			claims:           jwtgo.MapClaims{"iss": "https://issuer.example.com/", "aud": []string{"client", "other"}},
+			expectedIssuer:   "https://issuer.example.com/",
+			expectedClientID: "client",
+		},
+		{
+			name:          "case 2: no issuer",
+			claims:        jwtgo.MapClaims{"aud": "client"},
+			expectedError: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			token, err := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, tc.claims).SignedString([]byte("secret"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			issuer, clientID, err := idTokenIssuer(token)
+			if tc.expectedError {
+				if err == nil {
+					t.Fatalf("%s: error == nil, want non-nil", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+			if issuer != tc.expectedIssuer || clientID != tc.expectedClientID {
+				t.Fatalf("%s: issuer, client ID == %q, %q, want %q, %q", tc.name, issuer, clientID, tc.expectedIssuer, tc.expectedClientID)
+			}
+		})
+	}
+}
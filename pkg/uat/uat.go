@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff"
@@ -19,9 +18,11 @@ import (
 
 	"github.com/giantswarm/api-acceptance-test/pkg/client"
 	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+	"github.com/giantswarm/api-acceptance-test/pkg/k8s"
 	"github.com/giantswarm/api-acceptance-test/pkg/kubeconfig"
 	"github.com/giantswarm/api-acceptance-test/pkg/load"
-	"github.com/giantswarm/api-acceptance-test/pkg/shell"
+	"github.com/giantswarm/api-acceptance-test/pkg/metrics"
+	"github.com/giantswarm/api-acceptance-test/pkg/testapp"
 )
 
 // TestClient verifies whether the given client can authenticate.
@@ -43,6 +44,23 @@ func TestClient(giantSwarmClient *client.Client) error {
 	return nil
 }
 
+// GetInstallationName returns the name of the installation the client is
+// connected to.
+func GetInstallationName(giantSwarmClient *client.Client) (string, error) {
+	params := info.NewGetInfoParams()
+	authWriter, err := giantSwarmClient.AuthHeaderWriter()
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	infoResponse, err := giantSwarmClient.GSClientGen.Info.GetInfo(params, authWriter)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return infoResponse.Payload.General.InstallationName, nil
+}
+
 // CreateClusterUsingDefaults tests
 // - whether we can create a cluster
 // - whether defaults are applied as expected.
@@ -127,7 +145,7 @@ func CreateNodePoolUsingDefaults(giantSwarmClient *client.Client, clusterID stri
 	if len(creationResult.Payload.AvailabilityZones) == 0 {
 		cliutil.Complain(microerror.Maskf(assertionFailedError, "'availability_zones' in node pool creation response is empty"))
 	} else if len(creationResult.Payload.AvailabilityZones) > 1 {
-		cliutil.Complain(microerror.Maskf(assertionFailedError, "'availability_zones' has % items instead of 1", len(creationResult.Payload.AvailabilityZones)))
+		cliutil.Complain(microerror.Maskf(assertionFailedError, "'availability_zones' has %d items instead of 1", len(creationResult.Payload.AvailabilityZones)))
 	}
 
 	if creationResult.Payload.Scaling == nil {
@@ -235,86 +253,114 @@ func CreateNodePoolWithCustomParams(giantSwarmClient *client.Client, clusterID s
 	return creationResult.Payload.ID, nil
 }
 
+// KeyPairSpec defines the key pair to create.
+type KeyPairSpec struct {
+	Description              string
+	TTLHours                 int32
+	CertificateOrganizations string
+	CnPrefix                 string
+}
+
 // CreateKeyPair tests key pair creation for the new cluster
 // and stores away the key pair in a kubectl config file for later use.
 func CreateKeyPair(giantSwarmClient *client.Client, clusterID string, clusterAPIEndpoint string) (string, error) {
-	description := "test key pair"
-	req := &models.V4AddKeyPairRequest{
+	spec := KeyPairSpec{
+		Description:              "test key pair",
 		TTLHours:                 12,
-		Description:              &description,
 		CertificateOrganizations: "system:masters",
 		CnPrefix:                 "user@giantswarm.io",
 	}
+
+	path, _, err := CreateKeyPairWithSpec(giantSwarmClient, clusterID, clusterAPIEndpoint, spec)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return path, nil
+}
+
+// CreateKeyPairWithSpec creates a key pair as specified, validates the
+// returned certificates and stores the key pair in a kubectl config file.
+// Returns the kubeconfig path and the key pair ID.
+func CreateKeyPairWithSpec(giantSwarmClient *client.Client, clusterID string, clusterAPIEndpoint string, spec KeyPairSpec) (string, string, error) {
+	description := spec.Description
+	req := &models.V4AddKeyPairRequest{
+		TTLHours:                 spec.TTLHours,
+		Description:              &description,
+		CertificateOrganizations: spec.CertificateOrganizations,
+		CnPrefix:                 spec.CnPrefix,
+	}
 	params := key_pairs.NewAddKeyPairParams().WithClusterID(clusterID).WithBody(req)
 
 	authWriter, err := giantSwarmClient.AuthHeaderWriter()
 	if err != nil {
-		return "", microerror.Mask(err)
+		return "", "", microerror.Mask(err)
 	}
 
 	addKeyPairResponse, err := giantSwarmClient.GSClientGen.KeyPairs.AddKeyPair(params, authWriter)
 	if err != nil {
 		if _, ok := err.(*key_pairs.AddKeyPairServiceUnavailable); ok {
-			return "", microerror.Mask(notYetAvailableError)
+			return "", "", microerror.Mask(notYetAvailableError)
 		}
-		return "", microerror.Mask(err)
+		return "", "", microerror.Mask(err)
 	}
 
 	if addKeyPairResponse.Payload.ID == "" {
-		return "", microerror.Maskf(assertionFailedError, "'id' in key pair creation response is empty")
+		return "", "", microerror.Maskf(assertionFailedError, "'id' in key pair creation response is empty")
 	}
 
+	validateKeyPairCertificates(addKeyPairResponse.Payload, spec)
+
 	// store kubeconfig file
 	fs := afero.NewOsFs()
 	path := fmt.Sprintf("kubeconfig_uat_%s_%s.yaml", clusterID, cleanupKeyPairID(addKeyPairResponse.Payload.ID))
 	cliutil.PrintInfo("Storing the key pair in kubeconfig file %s", path)
 	err = kubeconfig.WriteKubeconfigFile(fs, path, clusterAPIEndpoint, addKeyPairResponse.Payload.CertificateAuthorityData, addKeyPairResponse.Payload.ClientCertificateData, addKeyPairResponse.Payload.ClientKeyData)
 	if err != nil {
-		return "", microerror.Mask(err)
+		return "", "", microerror.Mask(err)
 	}
 
 	cliutil.PrintSuccess("Key pair for cluster %s has been created with ID %s", clusterID, addKeyPairResponse.Payload.ID)
-	return path, nil
+	return path, addKeyPairResponse.Payload.ID, nil
 }
 
-// RunKubectlCommandToTestKeyPair used kubectl to get a list of cluster nodes and returns an error if that fails.
-func RunKubectlCommandToTestKeyPair(kubeconfigPath string) error {
-	out, exitCode, err := shell.RunCommand(context.Background(), "kubectl", []string{}, "--kubeconfig", kubeconfigPath, "get", "nodes")
+// TestKeyPairAccess lists the cluster nodes using the given Kubernetes client
+// and returns an error if that fails.
+func TestKeyPairAccess(k8sClient k8s.Interface) error {
+	nodes, err := k8sClient.ListNodes(context.Background(), "")
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	cliutil.PrintSuccess("kubectl get nodes exited with code %d and printed:\n\n", exitCode)
-	cliutil.PrintInfo(out)
+	cliutil.PrintSuccess("Listed %d nodes:", len(nodes))
+	for _, n := range nodes {
+		cliutil.PrintInfo("%s (ready: %v)", n.Name, k8s.IsNodeReady(n))
+	}
 	return nil
 }
 
-// DeployTestApp attempts to deploy a helloworld app on the cluster.
-// Returns the ingress URL of the app.
-func DeployTestApp(kubeconfigPath string, clusterAPIEndpoint string) (string, error) {
-	// cluster base domain based on API endpoint
-	clusterBaseDomain := strings.Replace(clusterAPIEndpoint, "https://api.", "", 1)
-
-	templatePath := "./testapp-manifest.yaml.template"
-	manifestPath := "./testapp-manifest.yaml"
-	fs := afero.NewOsFs()
-	templateData, err := afero.ReadFile(fs, templatePath)
+// DeployTestApp attempts to deploy a helloworld app on the cluster, rendering
+// the manifest template with the given values. API versions are chosen based
+// on the versions the cluster serves. Returns the ingress URL of the app.
+func DeployTestApp(k8sClient k8s.Interface, manifestTemplate string, values testapp.Values) (string, error) {
+	served, err := k8sClient.ServedAPIVersions(context.Background())
 	if err != nil {
 		return "", microerror.Mask(err)
 	}
+	values = values.WithServedAPIVersions(served)
+	cliutil.PrintInfo("Using %s for the PodDisruptionBudget and %s for the Ingress", values.PodDisruptionBudgetAPIVersion, values.IngressAPIVersion)
 
-	manifest := strings.Replace(string(templateData), "CLUSTER_BASE_DOMAIN", clusterBaseDomain, -1)
-	err = afero.WriteFile(fs, manifestPath, []byte(manifest), 0644)
+	manifest, err := testapp.Render(manifestTemplate, values)
 	if err != nil {
 		return "", microerror.Mask(err)
 	}
 
-	out, exitCode, err := shell.RunCommand(context.Background(), "kubectl", []string{}, "--kubeconfig", kubeconfigPath, "apply", "-f", manifestPath)
+	err = k8sClient.Apply(context.Background(), manifest)
 	if err != nil {
 		return "", microerror.Mask(err)
 	}
 
-	endpoint := "http://test." + clusterBaseDomain + "/delay/1"
+	endpoint := "http://" + values.Host() + "/delay/1"
 
 	// Wait for the ingress to be reachable.
 	start := time.Now()
@@ -339,25 +385,64 @@ func DeployTestApp(kubeconfigPath string, clusterAPIEndpoint string) (string, er
 	duration := time.Now().Sub(start)
 	cliutil.PrintInfo("Ingress at %s reached after %s", endpoint, duration)
 
-	cliutil.PrintSuccess("kubectl apply exited with code %d and printed:\n\n", exitCode)
-	cliutil.PrintInfo(out)
+	cliutil.PrintSuccess("Test app has been deployed")
 	return endpoint, nil
 }
 
-// CreateLoadOnIngress sets a constant load on the given URL.
-func CreateLoadOnIngress(ingressEndpoint string) {
-	go load.ProduceLoad(ingressEndpoint, 5*time.Hour, 100_000_000_000)
+// CreateLoadOnIngress starts a constant load on the given URL. If a profile is
+// given, its requests are sent instead of GETs of the URL, with their paths
+// resolved against it. Statistics are written to the given sinks every 10
+// seconds and every result is exported as metrics.
+// The load stops when ctx is done or the returned generator is stopped, which
+// then holds the results.
+func CreateLoadOnIngress(ctx context.Context, ingressEndpoint string, profile *load.Profile, sinks []load.Sink) (*load.Generator, error) {
+	config := load.Config{
+		URL: ingressEndpoint,
+		// GET /delay/1 takes at least a second, so 50 requests per second
+		// keep about 50 workers busy. The rest is headroom for slower
+		// responses, so that requests don't get dropped.
+		Workers: 75,
+		Rate:    50,
+		Sinks:   sinks,
+		Observe: metrics.ObserveLoadResult,
+	}
+	if profile != nil {
+		config = profile.Apply(config)
+	}
+
+	generator, err := load.New(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	err = generator.Start(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return generator, nil
 }
 
-// IncreaseTestAppReplicas increases the test app replicas.
-func IncreaseTestAppReplicas(kubeconfigPath string) error {
-	out, exitCode, err := shell.RunCommand(context.Background(), "kubectl", []string{}, "--kubeconfig", kubeconfigPath, "scale", "--replicas=5", "deployment/e2e-app")
+// testAppRolloutTimeout is how long the test app may take to roll out after
+// being scaled.
+const testAppRolloutTimeout = 5 * time.Minute
+
+// IncreaseTestAppReplicas increases the test app replicas by three and waits
+// until all replicas are available.
+func IncreaseTestAppReplicas(k8sClient k8s.Interface, values testapp.Values) error {
+	replicas := values.Replicas + 3
+	err := k8sClient.ScaleDeployment(context.Background(), values.Namespace, values.Name, int32(replicas))
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	cliutil.PrintSuccess("kubectl scale exited with code %d and printed:\n\n", exitCode)
-	cliutil.PrintInfo(out)
+	start := time.Now()
+	err = k8sClient.WaitForRollout(context.Background(), values.Namespace, values.Name, testAppRolloutTimeout)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cliutil.PrintSuccess("Test app has been scaled to %d replicas and rolled out after %s", replicas, time.Since(start).Round(time.Second))
 	return nil
 }
 
@@ -419,8 +504,8 @@ func ScaleNodePool(giantSwarmClient *client.Client, clusterID string, nodePoolID
 		if response.Payload.Scaling.Min != int64(min) {
 			cliutil.Complain(microerror.Maskf(assertionFailedError, "'scaling.min' in node pool modification response is not %d", min))
 		}
-		if response.Payload.Scaling.Min != int64(max) {
-			cliutil.Complain(microerror.Maskf(assertionFailedError, "'scaling.min' in node pool modification response is not %d", max))
+		if response.Payload.Scaling.Max != int64(max) {
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "'scaling.max' in node pool modification response is not %d", max))
 		}
 	}
 
@@ -466,3 +551,33 @@ func GetNodePoolDetails(giantSwarmClient *client.Client, clusterID string, nodeP
 
 	return details.Payload, nil
 }
+
+// GetClusterDetails returns details on a cluster.
+func GetClusterDetails(giantSwarmClient *client.Client, clusterID string) (*models.V5ClusterDetailsResponse, error) {
+	params := clusters.NewGetClusterV5Params().WithClusterID(clusterID)
+	authWriter, err := giantSwarmClient.AuthHeaderWriter()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	details, err := giantSwarmClient.GSClientGen.Clusters.GetClusterV5(params, authWriter)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return details.Payload, nil
+}
+
+// ListNodePools returns all node pools of a cluster.
+func ListNodePools(giantSwarmClient *client.Client, clusterID string) (models.V5GetNodePoolsResponse, error) {
+	params := node_pools.NewGetNodePoolsParams().WithClusterID(clusterID)
+	authWriter, err := giantSwarmClient.AuthHeaderWriter()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	list, err := giantSwarmClient.GSClientGen.NodePools.GetNodePools(params, authWriter)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list.Payload, nil
+}
@@ -1,9 +1,26 @@
 package uat
 
 import (
+	"net"
 	"strings"
+
+	"github.com/giantswarm/microerror"
 )
 
 func cleanupKeyPairID(id string) string {
 	return strings.Replace(id, ":", "", -1)
 }
+
+// subnetsOverlap returns true if the two given CIDR ranges share addresses.
+func subnetsOverlap(a string, b string) (bool, error) {
+	_, netA, err := net.ParseCIDR(a)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	_, netB, err := net.ParseCIDR(b)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return netA.Contains(netB.IP) || netB.Contains(netA.IP), nil
+}
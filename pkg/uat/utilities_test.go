@@ -0,0 +1,65 @@
+package uat
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_subnetsOverlap(t *testing.T) {
+	testCases := []struct {
+		name          string
+		a             string
+		b             string
+		expected      bool
+		expectedError bool
+	}{
+		{
+			name:     "case 0: neighbouring subnets",
+			a:        "10.1.0.0/24",
+			b:        "10.1.1.0/24",
+			expected: false,
+		},
+		{
+			name:     "case 1: identical subnets",
+			a:        "10.1.0.0/24",
+			b:        "10.1.0.0/24",
+			expected: true,
+		},
+		{
+			name:     "case 2: first contains second",
+			a:        "10.1.0.0/16",
+			b:        "10.1.5.0/24",
+			expected: true,
+		},
+		{
+			name:     "case 3: second contains first",
+			a:        "10.1.5.128/25",
+			b:        "10.1.5.0/24",
+			expected: true,
+		},
+		{
+			name:          "case 4: invalid CIDR",
+			a:             "10.1.0.0",
+			b:             "10.1.5.0/24",
+			expectedError: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			overlap, err := subnetsOverlap(tc.a, tc.b)
+			if tc.expectedError {
+				if err == nil {
+					t.Fatalf("%s: error == nil, want non-nil", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			}
+			if overlap != tc.expected {
+				t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, overlap)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package uat
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/api-acceptance-test/pkg/client"
+	"github.com/giantswarm/api-acceptance-test/pkg/cliutil"
+)
+
+// TestInputValidation sends invalid cluster and node pool creation and
+// modification requests and verifies that each one gets rejected with
+// status 400 and an error message.
+func TestInputValidation(giantSwarmClient *client.Client, clusterID string, nodePoolID string, ownerOrg string) error {
+	clustersPath := "/v5/clusters/"
+	nodePoolsPath := fmt.Sprintf("/v5/clusters/%s/nodepools/", clusterID)
+	nodePoolPath := fmt.Sprintf("/v5/clusters/%s/nodepools/%s/", clusterID, nodePoolID)
+
+	overlongName := strings.Repeat("a", 1000)
+
+	deleteCluster := func(id string) error {
+		return DeleteCluster(giantSwarmClient, id)
+	}
+	deleteNodePool := func(id string) error {
+		return DeleteNodePool(giantSwarmClient, clusterID, id)
+	}
+
+	testCases := []struct {
+		name string
+		// request sends the invalid request. If the API accepts it by
+		// mistake, the ID of the created resource is returned.
+		request func() (string, error)
+		// cleanup removes a resource created by mistake.
+		cleanup func(id string) error
+	}{
+		{
+			name: "AddClusterV5 with overlong name",
+			request: rawRequest(giantSwarmClient, http.MethodPost, clustersPath, map[string]interface{}{
+				"owner": ownerOrg,
+				"name":  overlongName,
+			}),
+			cleanup: deleteCluster,
+		},
+		{
+			name: "AddClusterV5 with unknown master availability zone",
+			request: rawRequest(giantSwarmClient, http.MethodPost, clustersPath, map[string]interface{}{
+				"owner": ownerOrg,
+				"master": map[string]interface{}{
+					"availability_zone": "nowhere-1x",
+				},
+			}),
+			cleanup: deleteCluster,
+		},
+		{
+			name: "AddClusterV5 with unknown field",
+			request: rawRequest(giantSwarmClient, http.MethodPost, clustersPath, map[string]interface{}{
+				"owner":         ownerOrg,
+				"unknown_field": "foo",
+			}),
+			cleanup: deleteCluster,
+		},
+		{
+			name: "AddNodePool with invalid instance type",
+			request: func() (string, error) {
+				return CreateNodePoolWithCustomParams(giantSwarmClient, clusterID, "x9.nonexisting", nil)
+			},
+			cleanup: deleteNodePool,
+		},
+		{
+			name: "AddNodePool with unknown availability zone",
+			request: func() (string, error) {
+				return CreateNodePoolWithCustomParams(giantSwarmClient, clusterID, "", []string{"nowhere-1x"})
+			},
+			cleanup: deleteNodePool,
+		},
+		{
+			name: "AddNodePool with scaling min > max",
+			request: rawRequest(giantSwarmClient, http.MethodPost, nodePoolsPath, map[string]interface{}{
+				"scaling": map[string]interface{}{
+					"min": 5,
+					"max": 2,
+				},
+			}),
+			cleanup: deleteNodePool,
+		},
+		{
+			name: "AddNodePool with negative volume sizes",
+			request: rawRequest(giantSwarmClient, http.MethodPost, nodePoolsPath, map[string]interface{}{
+				"node_spec": map[string]interface{}{
+					"volume_sizes_gb": map[string]interface{}{
+						"docker":  -10,
+						"kubelet": -10,
+					},
+				},
+			}),
+			cleanup: deleteNodePool,
+		},
+		{
+			name: "AddNodePool with overlong name",
+			request: rawRequest(giantSwarmClient, http.MethodPost, nodePoolsPath, map[string]interface{}{
+				"name": overlongName,
+			}),
+			cleanup: deleteNodePool,
+		},
+		{
+			name: "AddNodePool with unknown field",
+			request: rawRequest(giantSwarmClient, http.MethodPost, nodePoolsPath, map[string]interface{}{
+				"unknown_field": "foo",
+			}),
+			cleanup: deleteNodePool,
+		},
+		{
+			name: "ModifyNodePool with scaling min > max",
+			request: rawRequest(giantSwarmClient, http.MethodPatch, nodePoolPath, map[string]interface{}{
+				"scaling": map[string]interface{}{
+					"min": 5,
+					"max": 2,
+				},
+			}),
+		},
+		{
+			name: "ModifyNodePool with overlong name",
+			request: rawRequest(giantSwarmClient, http.MethodPatch, nodePoolPath, map[string]interface{}{
+				"name": overlongName,
+			}),
+		},
+		{
+			name: "ModifyNodePool with unknown field",
+			request: rawRequest(giantSwarmClient, http.MethodPatch, nodePoolPath, map[string]interface{}{
+				"unknown_field": "foo",
+			}),
+		},
+	}
+
+	failures := 0
+	for _, tc := range testCases {
+		id, err := tc.request()
+		if err == nil {
+			failures++
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "%s was accepted by the API", tc.name))
+
+			if tc.cleanup != nil && id != "" {
+				cliutil.Complain(tc.cleanup(id))
+			}
+			continue
+		}
+
+		statusCode, body := responseStatus(err)
+		if statusCode != http.StatusBadRequest {
+			failures++
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "%s was expected to fail with status 400, got %d: %s", tc.name, statusCode, err.Error()))
+			continue
+		}
+		if body == nil || body.Message == "" {
+			failures++
+			cliutil.Complain(microerror.Maskf(assertionFailedError, "%s was rejected without an error message", tc.name))
+			continue
+		}
+
+		cliutil.PrintSuccess("%s was rejected with code %q and message %q", tc.name, body.Code, body.Message)
+	}
+
+	if failures > 0 {
+		return microerror.Maskf(assertionFailedError, "%d of %d invalid requests were not rejected as expected", failures, len(testCases))
+	}
+
+	return nil
+}
+
+// rawRequest returns a function sending the given body as JSON to the API
+// and returning the ID of the resource in the response, if there is one.
+func rawRequest(giantSwarmClient *client.Client, method string, path string, body interface{}) func() (string, error) {
+	return func() (string, error) {
+		reqBody, err := json.Marshal(body)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		_, respBody, err := giantSwarmClient.DoRawRequest(method, path, reqBody)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		var resource struct {
+			ID string `json:"id"`
+		}
+		_ = json.Unmarshal(respBody, &resource)
+
+		return resource.ID, nil
+	}
+}